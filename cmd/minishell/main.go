@@ -1,31 +1,45 @@
 package main
 
 import (
+	"fmt"
 	"minishell/internal/application/services"
 	input_adapters "minishell/internal/infrastructure/adapters/input_adapters"
 	output_adapters "minishell/internal/infrastructure/adapters/output_adapters"
 	parser_adapters "minishell/internal/infrastructure/adapters/parser_adapters"
 	presenters "minishell/internal/infrastructure/adapters/presenters"
+	"os"
 )
 
 func main() {
+	// Разбор аргументов командной строки
+	cmdLine, err := input_adapters.ParseCommandLine(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "minishell:", err)
+		fmt.Fprintln(os.Stderr, "usage: minishell [-is] [-c command [name]] [script] [args ...]")
+		os.Exit(2)
+	}
+
 	// Инициализация адаптеров
 	systemRepo := output_adapters.NewSystemRepositoryAdapter()
 	commandParser := parser_adapters.NewCommandParserAdapter()
+	commandHelp := output_adapters.NewCommandHelpAdapter()
+	wordExpander := parser_adapters.NewWordExpanderAdapter()
 	shellPresenter := presenters.NewShellPresenterAdapter()
 
 	// Инициализация сервисов
-	commandService := services.NewCommandService(systemRepo)
+	commandService := services.NewCommandService(systemRepo, commandParser, wordExpander, shellPresenter)
 	shellService := services.NewShellService(
 		commandParser,
 		commandService,
 		systemRepo,
 		shellPresenter,
 	)
+	completionService := services.NewCompletionService(commandParser, systemRepo, commandHelp, wordExpander, commandService)
+	highlightService := services.NewHighlightService(commandParser, systemRepo)
 
 	// Инициализация контроллера
-	shellController := input_adapters.NewShellController(shellService, systemRepo)
+	shellController := input_adapters.NewShellController(shellService, completionService, highlightService, systemRepo)
 
 	// Запуск приложения
-	shellController.Run()
+	os.Exit(shellController.Run(cmdLine))
 }
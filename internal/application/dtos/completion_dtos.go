@@ -0,0 +1,20 @@
+package dtos
+
+// CompletionCandidateDTO - DTO варианта дополнения
+type CompletionCandidateDTO struct {
+	// Value - текст, заменяющий слово от его начала до курсора
+	Value string
+	// Display - имя варианта в списке
+	Display string
+	// Description - описание варианта, выводимое рядом с ним в списке
+	Description string
+	// NoSpace - не добавлять пробел после единственного варианта
+	NoSpace bool
+}
+
+// CompletionDTO - DTO результата дополнения
+type CompletionDTO struct {
+	// Start - смещение начала заменяемого текста в строке (в байтах)
+	Start      int
+	Candidates []CompletionCandidateDTO
+}
@@ -0,0 +1,10 @@
+package dtos
+
+// HighlightDTO - DTO фрагмента строки, выводимого цветом
+type HighlightDTO struct {
+	// Start и End - границы фрагмента в строке (в байтах)
+	Start int
+	End   int
+	// Style - параметры SGR escape-последовательности, например "1;32"
+	Style string
+}
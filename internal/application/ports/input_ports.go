@@ -1,16 +1,35 @@
 package ports
 
-import "minishell/internal/domain"
+import (
+	"minishell/internal/application/dtos"
+	"minishell/internal/domain"
+)
 
 // ShellInputPort - входящий порт для операций shell
 type ShellInputPort interface {
 	ExecuteCommand(input string, ctx *domain.ExecutionContext) error
+	IsInputComplete(input string) bool
+	ReadCommand(nextLine func() (string, error)) (string, error)
 	ShouldContinue(ctx *domain.ExecutionContext) bool
 	GetPrompt(ctx *domain.ExecutionContext) string
+	LoadHistory(ctx *domain.ExecutionContext)
+	SaveHistory(ctx *domain.ExecutionContext)
+	SuggestHistory(line string, ctx *domain.ExecutionContext) string
 }
 
 // CommandInputPort - входящий порт для выполнения команд
 type CommandInputPort interface {
+	ExecutePipelines(pipelines []*domain.Pipeline, ctx *domain.ExecutionContext) error
 	ExecutePipeline(pipeline *domain.Pipeline, ctx *domain.ExecutionContext) error
 	ExecuteSingleCommand(cmd *domain.Command, ctx *domain.ExecutionContext) error
 }
+
+// CompletionInputPort - входящий порт для дополнения ввода
+type CompletionInputPort interface {
+	Complete(line string, cursor int, ctx *domain.ExecutionContext) *dtos.CompletionDTO
+}
+
+// HighlightInputPort - входящий порт для подсветки синтаксиса ввода
+type HighlightInputPort interface {
+	Highlight(line string, ctx *domain.ExecutionContext) []dtos.HighlightDTO
+}
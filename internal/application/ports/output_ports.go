@@ -4,23 +4,54 @@ import "minishell/internal/domain"
 
 // CommandParserOutputPort - исходящий порт для парсинга команд
 type CommandParserOutputPort interface {
-	Parse(input string, env map[string]string) ([]*domain.Pipeline, error)
+	Parse(input string, aliases map[string]string) ([]*domain.Pipeline, error)
+	IsComplete(input string) bool
+	ParseAssignment(word string) (*domain.Assignment, error)
+	ExpandHistory(input string, history *domain.History) (string, bool, error)
+	ParseCompletionLine(line string, cursor int) *domain.CompletionLine
+	ParseHelpOptions(help string) []domain.CommandOption
+	ParseManPageOptions(page string) []domain.CommandOption
+	ParseHighlightLine(line string) []domain.HighlightSpan
+}
+
+// WordExpanderOutputPort - исходящий порт для раскрытия слов команды
+type WordExpanderOutputPort interface {
+	ExpandWords(words []string, ctx *domain.ExecutionContext) ([]string, error)
+	ExpandWord(word string, ctx *domain.ExecutionContext) (string, error)
+	ExpandValue(word string, ctx *domain.ExecutionContext) (string, error)
+	ExpandSubscript(name, subscript string, ctx *domain.ExecutionContext) (string, error)
+	EvaluateArithmetic(expr string, ctx *domain.ExecutionContext) (int, error)
+	MatchPattern(pattern, s string, ctx *domain.ExecutionContext) bool
 }
 
 // SystemRepositoryOutputPort - исходящий порт для системных операций
 type SystemRepositoryOutputPort interface {
 	ExecuteCommand(cmd *domain.Command, input []byte) ([]byte, int, error)
+	StartPipeline(commands []*domain.Command) (int, error)
 	ChangeDirectory(path string) error
 	GetCurrentDirectory() (string, error)
 	GetEnvironment() map[string]string
 	KillProcess(pid int) error
 	GetProcessList() ([]domain.ProcessInfo, error)
+	ReadFile(path string) ([]byte, error)
+	WriteFile(path string, data []byte, append bool) error
+	WritePrivateFile(path string, data []byte) error
+	ReadDirectory(path string) ([]domain.FileInfo, error)
+	StatFile(path string) (domain.FileInfo, error)
+	CreateDirectory(path string) error
+}
+
+// CommandHelpOutputPort - исходящий порт для справки внешних команд
+type CommandHelpOutputPort interface {
+	ReadManPage(name string, manPath string) (string, error)
+	RunHelp(path string) (string, error)
 }
 
 // ShellPresenterOutputPort - исходящий порт для представления результатов
 type ShellPresenterOutputPort interface {
 	ShowPrompt(prompt string)
 	ShowOutput(output string)
+	ShowMessage(message string)
 	ShowError(error string)
 	ShowExitCode(code int)
 }
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"fmt"
+	"minishell/internal/application/dtos"
+	"minishell/internal/application/ports"
+	"minishell/internal/domain"
+	"minishell/pkg/constants"
+	"path/filepath"
+	"strings"
+)
+
+// completeCommandOptions возвращает параметры внешней команды,
+// начинающиеся с дополняемого слова, вместе с их описаниями
+func (s *CompletionService) completeCommandOptions(word *domain.CompletionLine, ctx *domain.ExecutionContext) []dtos.CompletionCandidateDTO {
+	var candidates []dtos.CompletionCandidateDTO
+	for _, option := range s.commandOptions(word.Command, ctx) {
+		if !strings.HasPrefix(option.Name, word.Prefix) {
+			continue
+		}
+		candidates = append(candidates, dtos.CompletionCandidateDTO{
+			Value:       replacementText(word, 0, option.Name),
+			Display:     option.Name,
+			Description: option.Description,
+			NoSpace:     strings.HasSuffix(option.Name, "="),
+		})
+	}
+	return candidates
+}
+
+// commandOptions возвращает параметры внешней команды из ее man-страницы
+// или вывода --help. Результат хранится в кэше на диске и используется,
+// пока не изменились размер и время изменения исполняемого файла.
+func (s *CompletionService) commandOptions(command string, ctx *domain.ExecutionContext) []domain.CommandOption {
+	if command == "" || domain.NewCommand(command).IsBuiltin() {
+		return nil
+	}
+	if _, ok := ctx.GetFunction(command); ok {
+		return nil
+	}
+	path, file, ok := findExecutable(s.system, command, ctx)
+	if !ok {
+		return nil
+	}
+
+	version := fmt.Sprintf("%s\t%d\t%d", path, file.Size, file.ModTime.UnixNano())
+	cacheFile := optionsCacheFile(path, ctx)
+	if cacheFile != "" {
+		if options, ok := s.readOptionsCache(cacheFile, version); ok {
+			return options
+		}
+	}
+
+	var options []domain.CommandOption
+	if page, err := s.help.ReadManPage(filepath.Base(path), ctx.GetEnv("MANPATH")); err == nil {
+		options = s.parser.ParseManPageOptions(page)
+	}
+	if len(options) == 0 {
+		if help, err := s.help.RunHelp(path); err == nil {
+			options = s.parser.ParseHelpOptions(help)
+		}
+	}
+
+	if cacheFile != "" {
+		s.writeOptionsCache(cacheFile, version, options)
+	}
+	return options
+}
+
+// findExecutable находит исполняемый файл команды: по пути, если имя
+// содержит /, иначе в каталогах PATH
+func findExecutable(system ports.SystemRepositoryOutputPort, command string, ctx *domain.ExecutionContext) (string, domain.FileInfo, bool) {
+	var paths []string
+	if strings.Contains(command, "/") {
+		dir, name := splitCompletionPath(command)
+		paths = []string{filepath.Join(completionDirectory(dir, ctx), name)}
+	} else {
+		for _, dir := range filepath.SplitList(ctx.GetEnv("PATH")) {
+			paths = append(paths, filepath.Join(completionDirectory(dir, ctx), command))
+		}
+	}
+
+	for _, path := range paths {
+		if file, err := system.StatFile(path); err == nil && file.IsExecutable {
+			return path, file, true
+		}
+	}
+	return "", domain.FileInfo{}, false
+}
+
+// optionsCacheFile возвращает файл кэша параметров команды в
+// $XDG_CACHE_HOME/minishell/options или ~/.cache/minishell/options
+func optionsCacheFile(path string, ctx *domain.ExecutionContext) string {
+	base := ctx.GetEnv("XDG_CACHE_HOME")
+	if base == "" {
+		home := ctx.GetEnv("HOME")
+		if home == "" {
+			return ""
+		}
+		base = filepath.Join(home, constants.DefaultCacheDir)
+	}
+	return filepath.Join(base, constants.OptionsCacheDir, strings.ReplaceAll(path, "/", "_"))
+}
+
+// readOptionsCache читает параметры из файла кэша; первая строка файла -
+// версия исполняемого файла, остальные - имя и описание через табуляцию
+func (s *CompletionService) readOptionsCache(cacheFile, version string) ([]domain.CommandOption, bool) {
+	data, err := s.system.ReadFile(cacheFile)
+	if err != nil {
+		return nil, false
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if lines[0] != version {
+		return nil, false
+	}
+
+	var options []domain.CommandOption
+	for _, line := range lines[1:] {
+		name, description, _ := strings.Cut(line, "\t")
+		options = append(options, domain.CommandOption{Name: name, Description: description})
+	}
+	return options, true
+}
+
+// writeOptionsCache сохраняет параметры в файл кэша; пустой список
+// тоже сохраняется, чтобы не искать справку повторно
+func (s *CompletionService) writeOptionsCache(cacheFile, version string, options []domain.CommandOption) {
+	var out strings.Builder
+	out.WriteString(version + "\n")
+	for _, option := range options {
+		description := strings.Map(func(r rune) rune {
+			if r == '\t' || r == '\n' {
+				return ' '
+			}
+			return r
+		}, option.Description)
+		out.WriteString(option.Name + "\t" + description + "\n")
+	}
+
+	if err := s.system.CreateDirectory(filepath.Dir(cacheFile)); err != nil {
+		return
+	}
+	_ = s.system.WriteFile(cacheFile, []byte(out.String()), false)
+}
@@ -2,30 +2,113 @@ package services
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"minishell/internal/application/ports"
 	"minishell/internal/domain"
+	"minishell/pkg/constants"
+	"minishell/pkg/utils"
+	"os"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 )
 
 // CommandService - application service для выполнения команд
 type CommandService struct {
-	system ports.SystemRepositoryOutputPort
+	system    ports.SystemRepositoryOutputPort
+	parser    ports.CommandParserOutputPort
+	expander  ports.WordExpanderOutputPort
+	presenter ports.ShellPresenterOutputPort
+	// stdout и stdin подменяются при выполнении функций внутри пайплайнов
+	// и с редиректами; stdin == nil означает стандартный ввод shell
+	stdout io.Writer
+	stdin  []byte
 }
 
 // NewCommandService создает новый сервис команд
-func NewCommandService(system ports.SystemRepositoryOutputPort) *CommandService {
+func NewCommandService(
+	system ports.SystemRepositoryOutputPort,
+	parser ports.CommandParserOutputPort,
+	expander ports.WordExpanderOutputPort,
+	presenter ports.ShellPresenterOutputPort,
+) *CommandService {
 	return &CommandService{
-		system: system,
+		system:    system,
+		parser:    parser,
+		expander:  expander,
+		presenter: presenter,
+		stdout:    os.Stdout,
 	}
 }
 
+// ExecutePipelines выполняет список пайплайнов с учетом логических операторов
+func (s *CommandService) ExecutePipelines(pipelines []*domain.Pipeline, ctx *domain.ExecutionContext) error {
+	// Сохраняем исходный exit code для правильной работы логических операторов
+	lastExitCode := ctx.LastExitCode
+
+	for i, pipeline := range pipelines {
+		if ctx.ShouldInterrupt() {
+			break
+		}
+
+		// Для первого пайплайна всегда выполняем, для остальных - проверяем оператор
+		if i > 0 && !pipeline.ShouldContinueExecution(lastExitCode) {
+			continue
+		}
+
+		if err := s.ExecutePipeline(pipeline, ctx); err != nil {
+			// Ошибка раскрытия параметра прерывает всю строку команд
+			var expansionErr *domain.ExpansionError
+			if errors.As(err, &expansionErr) {
+				ctx.UpdateExitCode(1)
+				return err
+			}
+			s.presenter.ShowError("execution error: " + err.Error())
+			if errors.Is(err, domain.ErrCommandNotFound) {
+				ctx.UpdateExitCode(127)
+			} else {
+				ctx.UpdateExitCode(1)
+			}
+		}
+
+		// Обновляем lastExitCode после каждого выполненного пайплайна
+		lastExitCode = ctx.LastExitCode
+	}
+
+	return nil
+}
+
 // ExecutePipeline выполняет пайплайн команд
 func (s *CommandService) ExecutePipeline(pipeline *domain.Pipeline, ctx *domain.ExecutionContext) error {
+	// Фоновый пайплайн раскрывается один раз: если его нельзя запустить в
+	// фоне, выполняются уже раскрытые команды
+	var expanded []*domain.Command
+	if last := pipeline.Commands[len(pipeline.Commands)-1]; last != nil && last.Background {
+		var started bool
+		var err error
+		if expanded, started, err = s.startBackground(pipeline.Commands, ctx); started || err != nil {
+			return err
+		}
+	}
+
 	if pipeline.IsSingleCommand() {
+		if expanded != nil {
+			return s.executeExpandedCommand(expanded[0], ctx)
+		}
 		return s.ExecuteSingleCommand(pipeline.Commands[0], ctx)
 	}
-	return s.executePipeSequence(pipeline.Commands, ctx)
+
+	// Команды пайплайна выполняются как в подоболочке: ошибка раскрытия
+	// параметра завершает только свою команду, а не весь shell
+	err := s.executePipeSequence(pipeline.Commands, expanded, ctx)
+	var expansionErr *domain.ExpansionError
+	if errors.As(err, &expansionErr) {
+		return errors.New(err.Error())
+	}
+	return err
 }
 
 // ExecuteSingleCommand выполняет одиночную команду
@@ -35,11 +118,48 @@ func (s *CommandService) ExecuteSingleCommand(cmd *domain.Command, ctx *domain.E
 		return fmt.Errorf("nil command")
 	}
 
+	if cmd.IsFunctionDefinition() {
+		ctx.DefineFunction(cmd.Function)
+		ctx.UpdateExitCode(0)
+		return nil
+	}
+
+	cmd, err := s.expandCommand(cmd, ctx)
+	if err != nil {
+		ctx.UpdateExitCode(1)
+		return err
+	}
+	return s.executeExpandedCommand(cmd, ctx)
+}
+
+// executeExpandedCommand выполняет одиночную команду с уже раскрытыми словами
+func (s *CommandService) executeExpandedCommand(cmd *domain.Command, ctx *domain.ExecutionContext) error {
+	// Присваивания без команды меняют переменные shell
+	if cmd.Name == "" {
+		if err := s.assignVariables(cmd.Assignments, ctx); err != nil {
+			ctx.UpdateExitCode(1)
+			return err
+		}
+		ctx.UpdateExitCode(0)
+		return nil
+	}
+
+	// Функции имеют приоритет над встроенными и внешними командами
+	if fn, ok := ctx.GetFunction(cmd.Name); ok {
+		return s.withTemporaryVariables(cmd, ctx, func() error {
+			return s.runWithRedirects(cmd, ctx, func() error {
+				return s.callFunction(fn, cmd.Args, ctx)
+			})
+		})
+	}
+
 	if cmd.IsBuiltin() {
-		return s.executeBuiltinCommand(cmd, ctx)
+		return s.withTemporaryVariables(cmd, ctx, func() error {
+			return s.executeBuiltinCommand(cmd, ctx)
+		})
 	}
 
-	output, exitCode, err := s.system.ExecuteCommand(cmd, nil)
+	output, exitCode, err := s.system.ExecuteCommand(cmd, s.takeInput())
 	if err != nil {
 		ctx.UpdateExitCode(exitCode)
 		// Возвращаем ошибку только если это не "нормальная" ошибка выполнения
@@ -52,35 +172,153 @@ func (s *CommandService) ExecuteSingleCommand(cmd *domain.Command, ctx *domain.E
 
 	// Выводим результат только если нет редиректа вывода
 	if cmd.Output == "" && len(output) > 0 {
-		fmt.Print(string(output))
+		fmt.Fprint(s.stdout, string(output))
 	}
 
 	return nil
 }
 
-// executePipeSequence выполняет последовательность команд с пайпами
-func (s *CommandService) executePipeSequence(commands []*domain.Command, ctx *domain.ExecutionContext) error {
-	var input []byte
+// expandCommand возвращает копию команды с раскрытыми словами
+func (s *CommandService) expandCommand(cmd *domain.Command, ctx *domain.ExecutionContext) (*domain.Command, error) {
+	words, err := s.expander.ExpandWords([]string{cmd.Name}, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Аргументы-присваивания declare раскрываются самой командой
+	declaration := len(words) > 0 && slices.Contains(constants.DeclarationBuiltins, words[0])
+	for _, arg := range cmd.Args {
+		if declaration {
+			if assignment, _ := s.parser.ParseAssignment(arg); assignment != nil {
+				words = append(words, arg)
+				continue
+			}
+		}
+
+		expanded, err := s.expander.ExpandWords([]string{arg}, ctx)
+		if err != nil {
+			return nil, err
+		}
+		words = append(words, expanded...)
+	}
+
+	expanded := domain.NewCommand("")
+	if len(words) > 0 {
+		expanded.Name = words[0]
+		expanded.Args = words[1:]
+	}
+	expanded.Append = cmd.Append
+	expanded.Background = cmd.Background
+	expanded.Environment = ctx.ExportedEnvironment()
+
+	// Присваивания без команды выполняются позже как есть; присваивания перед
+	// командой действуют только на нее, поэтому их значения раскрываются сразу
+	expanded.Assignments = cmd.Assignments
+	if expanded.Name != "" && len(cmd.Assignments) > 0 {
+		if expanded.Assignments, err = s.expandAssignments(cmd.Assignments, ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if cmd.Input != "" {
+		if expanded.Input, err = s.expander.ExpandWord(cmd.Input, ctx); err != nil {
+			return nil, err
+		}
+	}
+	if cmd.Output != "" {
+		if expanded.Output, err = s.expander.ExpandWord(cmd.Output, ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return expanded, nil
+}
+
+// startBackground запускает пайплайн внешних команд в фоне и запоминает
+// PID для $!. Функции и встроенные команды выполняются в текущем shell,
+// поэтому для них started == false и возвращаются раскрытые команды, которые
+// выполняются как обычный пайплайн. Определение функции не раскрывается.
+func (s *CommandService) startBackground(commands []*domain.Command, ctx *domain.ExecutionContext) ([]*domain.Command, bool, error) {
+	for _, cmd := range commands {
+		if cmd == nil || cmd.IsFunctionDefinition() {
+			return nil, false, nil
+		}
+	}
+
+	expanded := make([]*domain.Command, 0, len(commands))
+	external := true
+	for _, cmd := range commands {
+		cmd, err := s.expandCommand(cmd, ctx)
+		if err != nil {
+			ctx.UpdateExitCode(1)
+			return nil, true, err
+		}
+		if _, ok := ctx.GetFunction(cmd.Name); ok || cmd.Name == "" || cmd.IsBuiltin() {
+			external = false
+		}
+		expanded = append(expanded, cmd)
+	}
+	if !external {
+		return expanded, false, nil
+	}
+
+	pid, err := s.system.StartPipeline(expanded)
+	if err != nil {
+		ctx.UpdateExitCode(127)
+		return nil, true, err
+	}
+
+	ctx.UpdateLastBackgroundPID(pid)
+	ctx.UpdateExitCode(0)
+	return nil, true, nil
+}
+
+// executePipeSequence выполняет последовательность команд с пайпами. Каждая
+// команда выполняется как в подоболочке: в копии контекста, и текущая
+// директория после нее восстанавливается, поэтому присваивания, функции и
+// cd внутри пайплайна не влияют на shell. Если expanded не nil, в нем уже
+// раскрытые команды пайплайна.
+func (s *CommandService) executePipeSequence(commands, expanded []*domain.Command, ctx *domain.ExecutionContext) error {
+	input := s.takeInput()
 	var lastExitCode int
 
+	dir, dirErr := s.system.GetCurrentDirectory()
+
 	for i, cmd := range commands {
 		if cmd == nil {
 			ctx.UpdateExitCode(1)
 			return fmt.Errorf("nil command in pipeline")
 		}
 
-		output, exitcode, err := s.system.ExecuteCommand(cmd, input)
+		subshell := ctx.Clone()
+		var err error
+		if expanded != nil {
+			cmd = expanded[i]
+		} else if cmd, err = s.expandCommand(cmd, subshell); err != nil {
+			ctx.UpdateExitCode(1)
+			return err
+		}
+		output, exitcode, err := s.executePipelineCommand(cmd, input, subshell)
+		if dirErr == nil {
+			if current, err := s.system.GetCurrentDirectory(); err == nil && current != dir {
+				s.system.ChangeDirectory(dir)
+			}
+		}
 		if err != nil {
 			ctx.UpdateExitCode(1)
 			return err
 		}
 
+		// Следующая команда читает из пайпа, даже если вывод пуст
 		input = output
+		if input == nil {
+			input = []byte{}
+		}
 		lastExitCode = exitcode
 
 		// Выводим результат только для последней команды в пайплайне и если нет редиректа
 		if i == len(commands)-1 && cmd.Output == "" && len(output) > 0 {
-			fmt.Print(string(output))
+			fmt.Fprint(s.stdout, string(output))
 		}
 	}
 
@@ -88,40 +326,158 @@ func (s *CommandService) executePipeSequence(commands []*domain.Command, ctx *do
 	return nil
 }
 
-// executeBuiltinCommand выполняет встроенную команду
-func (s *CommandService) executeBuiltinCommand(cmd *domain.Command, ctx *domain.ExecutionContext) error {
-	// Для встроенных команд с редиректом вывода используем системный адаптер
-	if cmd.Output != "" || cmd.Input != "" {
-		output, exitCode, err := s.system.ExecuteCommand(cmd, nil)
+// executePipelineCommand выполняет одну раскрытую команду пайплайна в
+// контексте подоболочки; возвращает ее вывод и код завершения
+func (s *CommandService) executePipelineCommand(cmd *domain.Command, input []byte, ctx *domain.ExecutionContext) ([]byte, int, error) {
+	switch fn, ok := ctx.GetFunction(cmd.Name); {
+	case cmd.Name == "":
+		return nil, 0, nil
+	case ok:
+		output, err := s.captureOutput(input, func() error {
+			return s.withTemporaryVariables(cmd, ctx, func() error {
+				return s.runWithRedirects(cmd, ctx, func() error {
+					return s.callFunction(fn, cmd.Args, ctx)
+				})
+			})
+		})
+		return output, ctx.LastExitCode, err
+	case cmd.IsBuiltin() && cmd.Name != constants.CmdExit:
+		output, err := s.captureOutput(input, func() error {
+			return s.withTemporaryVariables(cmd, ctx, func() error {
+				return s.executeBuiltinCommand(cmd, ctx)
+			})
+		})
+		return output, ctx.LastExitCode, err
+	default:
+		return s.system.ExecuteCommand(cmd, input)
+	}
+}
+
+// callFunction вызывает функцию shell с позиционными параметрами
+func (s *CommandService) callFunction(fn *domain.Function, args []string, ctx *domain.ExecutionContext) error {
+	if ctx.CallDepth() >= constants.MaxFunctionDepth {
+		ctx.UpdateExitCode(1)
+		return fmt.Errorf("%s: maximum function nesting level exceeded (%d)", fn.Name, constants.MaxFunctionDepth)
+	}
+
+	ctx.PushFrame(domain.NewCallFrame(fn, args))
+	defer ctx.PopFrame()
+
+	// Код завершения не сбрасывается: return без аргумента возвращает
+	// код последней команды, в том числе выполненной до вызова
+	err := s.ExecutePipelines(fn.Body, ctx)
+
+	// return завершает только текущую функцию
+	ctx.IsReturning = false
+	return err
+}
+
+// runWithRedirects выполняет встроенную команду или функцию с учетом редиректов
+func (s *CommandService) runWithRedirects(cmd *domain.Command, ctx *domain.ExecutionContext, run func() error) error {
+	if cmd.Input == "" && cmd.Output == "" {
+		return run()
+	}
+
+	input := s.stdin
+	if cmd.Input != "" {
+		data, err := s.system.ReadFile(cmd.Input)
 		if err != nil {
-			ctx.UpdateExitCode(exitCode)
+			ctx.UpdateExitCode(1)
 			return err
 		}
-		ctx.UpdateExitCode(exitCode)
+		input = data
+	}
 
-		// Выводим результат только если нет редиректа вывода
-		if cmd.Output == "" && len(output) > 0 {
-			fmt.Print(string(output))
-		}
-		return nil
+	output, runErr := s.captureOutput(input, run)
+
+	if cmd.Output == "" {
+		fmt.Fprint(s.stdout, string(output))
+		return runErr
 	}
 
-	// Обычное выполнение встроенных команд без редиректов
-	switch cmd.Name {
-	case "cd":
-		return s.executeCD(cmd, ctx)
-	case "pwd":
-		return s.executePWD(ctx)
-	case "echo":
-		return s.executeEcho(cmd, ctx)
-	case "kill":
-		return s.executeKill(cmd, ctx)
-	case "ps":
-		return s.executePS(ctx)
-	default:
+	if err := s.system.WriteFile(cmd.Output, output, cmd.Append); err != nil {
 		ctx.UpdateExitCode(1)
-		return fmt.Errorf("unknown builtin command: %s", cmd.Name)
+		return err
+	}
+	return runErr
+}
+
+// captureOutput выполняет функцию, перехватывая ее вывод и подставляя ввод
+func (s *CommandService) captureOutput(input []byte, run func() error) ([]byte, error) {
+	prevOut, prevIn := s.stdout, s.stdin
+	defer func() {
+		s.stdout, s.stdin = prevOut, prevIn
+	}()
+
+	var buf bytes.Buffer
+	s.stdout, s.stdin = &buf, input
+
+	err := run()
+	return buf.Bytes(), err
+}
+
+// takeInput возвращает подставленный ввод; он достается только первой читающей команде
+func (s *CommandService) takeInput() []byte {
+	input := s.stdin
+	if input != nil {
+		s.stdin = []byte{}
 	}
+	return input
+}
+
+// executeBuiltinCommand выполняет встроенную команду
+func (s *CommandService) executeBuiltinCommand(cmd *domain.Command, ctx *domain.ExecutionContext) error {
+	return s.runWithRedirects(cmd, ctx, func() error {
+		switch cmd.Name {
+		case "cd":
+			return s.executeCD(cmd, ctx)
+		case "pwd":
+			return s.executePWD(ctx)
+		case "echo":
+			return s.executeEcho(cmd, ctx)
+		case "kill":
+			return s.executeKill(cmd, ctx)
+		case "ps":
+			return s.executePS(ctx)
+		case "local":
+			return s.executeLocal(cmd, ctx)
+		case "return":
+			return s.executeReturn(cmd, ctx)
+		case "alias":
+			return s.executeAlias(cmd, ctx)
+		case "unalias":
+			return s.executeUnalias(cmd, ctx)
+		case "shopt":
+			return s.executeShopt(cmd, ctx)
+		case "set":
+			return s.executeSet(cmd, ctx)
+		case "shift":
+			return s.executeShift(cmd, ctx)
+		case "unset":
+			return s.executeUnset(cmd, ctx)
+		case "declare", "typeset":
+			return s.executeDeclare(cmd, ctx)
+		case "export":
+			return s.executeExport(cmd, ctx)
+		case "env":
+			return s.executeEnv(cmd, ctx)
+		case "readonly":
+			return s.executeReadonly(cmd, ctx)
+		case "exit":
+			return s.executeExit(cmd, ctx)
+		case "source", ".":
+			return s.executeSource(cmd, ctx)
+		case "history":
+			return s.executeHistory(cmd, ctx)
+		case "complete":
+			return s.executeComplete(cmd, ctx)
+		case "compgen":
+			return s.executeCompgen(cmd, ctx)
+		default:
+			ctx.UpdateExitCode(1)
+			return fmt.Errorf("unknown builtin command: %s", cmd.Name)
+		}
+	})
 }
 
 // executeCD выполняет команду cd
@@ -142,10 +498,15 @@ func (s *CommandService) executeCD(cmd *domain.Command, ctx *domain.ExecutionCon
 		return err
 	}
 
+	// PWD и OLDPWD используются в подстановках ~+ и ~-
+	previous := ctx.CurrentDir
 	if dir, err := s.system.GetCurrentDirectory(); err == nil {
 		ctx.UpdateCurrentDir(dir)
+		ctx.SetEnv("OLDPWD", previous)
+		ctx.SetEnv("PWD", dir)
 	}
 
+	ctx.UpdateExitCode(0)
 	return nil
 }
 
@@ -157,7 +518,7 @@ func (s *CommandService) executePWD(ctx *domain.ExecutionContext) error {
 		return err
 	}
 
-	fmt.Println(dir)
+	fmt.Fprintln(s.stdout, dir)
 	ctx.UpdateExitCode(0)
 	return nil
 }
@@ -172,7 +533,7 @@ func (s *CommandService) executeEcho(cmd *domain.Command, ctx *domain.ExecutionC
 		output.WriteString(arg)
 	}
 
-	fmt.Println(output.String())
+	fmt.Fprintln(s.stdout, output.String())
 	ctx.UpdateExitCode(0)
 	return nil
 }
@@ -206,11 +567,342 @@ func (s *CommandService) executePS(ctx *domain.ExecutionContext) error {
 		return err
 	}
 
-	fmt.Println("PID\tCMD")
+	fmt.Fprintln(s.stdout, "PID\tCMD")
 	for _, proc := range processes {
-		fmt.Printf("%d\t%s\n", proc.PID, proc.Cmd)
+		fmt.Fprintf(s.stdout, "%d\t%s\n", proc.PID, proc.Cmd)
 	}
 
 	ctx.UpdateExitCode(0)
 	return nil
 }
+
+// executeLocal выполняет команду local: объявляет переменные текущей
+// функции с теми же флагами, что и declare
+func (s *CommandService) executeLocal(cmd *domain.Command, ctx *domain.ExecutionContext) error {
+	if ctx.CurrentFrame() == nil {
+		ctx.UpdateExitCode(1)
+		return fmt.Errorf("local: can only be used in a function")
+	}
+
+	opts, args, err := parseDeclareOptions(cmd.Name, cmd.Args, "aAilnrux")
+	if err != nil {
+		ctx.UpdateExitCode(2)
+		return err
+	}
+
+	ctx.UpdateExitCode(s.declareVariables(cmd.Name, args, opts, ctx))
+	return nil
+}
+
+// executeExit выполняет команду exit [n]: без аргумента shell завершается
+// с кодом последней команды
+func (s *CommandService) executeExit(cmd *domain.Command, ctx *domain.ExecutionContext) error {
+	ctx.Stop()
+	if len(cmd.Args) == 0 {
+		return nil
+	}
+
+	n, err := strconv.Atoi(cmd.Args[0])
+	if err != nil {
+		ctx.UpdateExitCode(2)
+		return fmt.Errorf("exit: %s: numeric argument required", cmd.Args[0])
+	}
+	if len(cmd.Args) > 1 {
+		ctx.IsRunning = true
+		ctx.UpdateExitCode(1)
+		return fmt.Errorf("exit: too many arguments")
+	}
+
+	ctx.UpdateExitCode(n & 0xff)
+	return nil
+}
+
+// executeReturn выполняет команду return
+func (s *CommandService) executeReturn(cmd *domain.Command, ctx *domain.ExecutionContext) error {
+	if ctx.CurrentFrame() == nil && ctx.SourceDepth == 0 {
+		ctx.UpdateExitCode(1)
+		return fmt.Errorf("return: can only `return' from a function or sourced script")
+	}
+
+	code := ctx.LastExitCode
+	if len(cmd.Args) > 0 {
+		n, err := strconv.Atoi(cmd.Args[0])
+		if err != nil {
+			ctx.RequestReturn(2)
+			return fmt.Errorf("return: %s: numeric argument required", cmd.Args[0])
+		}
+		code = n & 0xff
+	}
+
+	ctx.RequestReturn(code)
+	return nil
+}
+
+// executeAlias выполняет команду alias
+func (s *CommandService) executeAlias(cmd *domain.Command, ctx *domain.ExecutionContext) error {
+	args := cmd.Args
+	if len(args) > 0 && args[0] == "-p" {
+		args = args[1:]
+	}
+
+	if len(args) == 0 {
+		names := make([]string, 0, len(ctx.Aliases))
+		for name := range ctx.Aliases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Fprintf(s.stdout, "alias %s=%s\n", name, utils.ShellQuote(ctx.Aliases[name]))
+		}
+		ctx.UpdateExitCode(0)
+		return nil
+	}
+
+	exitCode := 0
+	for _, arg := range args {
+		name, value, hasValue := strings.Cut(arg, "=")
+		if !hasValue {
+			value, ok := ctx.GetAlias(name)
+			if !ok {
+				s.presenter.ShowError(fmt.Sprintf("alias: %s: not found", name))
+				exitCode = 1
+				continue
+			}
+			fmt.Fprintf(s.stdout, "alias %s=%s\n", name, utils.ShellQuote(value))
+			continue
+		}
+
+		if !isValidAliasName(name) {
+			s.presenter.ShowError(fmt.Sprintf("alias: `%s': invalid alias name", name))
+			exitCode = 1
+			continue
+		}
+		ctx.SetAlias(name, value)
+	}
+
+	ctx.UpdateExitCode(exitCode)
+	return nil
+}
+
+// executeUnalias выполняет команду unalias
+func (s *CommandService) executeUnalias(cmd *domain.Command, ctx *domain.ExecutionContext) error {
+	if len(cmd.Args) == 0 {
+		ctx.UpdateExitCode(2)
+		return fmt.Errorf("unalias: usage: unalias [-a] name [name ...]")
+	}
+
+	if cmd.Args[0] == "-a" {
+		ctx.ClearAliases()
+		ctx.UpdateExitCode(0)
+		return nil
+	}
+
+	exitCode := 0
+	for _, name := range cmd.Args {
+		if !ctx.UnsetAlias(name) {
+			s.presenter.ShowError(fmt.Sprintf("unalias: %s: not found", name))
+			exitCode = 1
+		}
+	}
+
+	ctx.UpdateExitCode(exitCode)
+	return nil
+}
+
+// executeShopt выполняет команду shopt
+func (s *CommandService) executeShopt(cmd *domain.Command, ctx *domain.ExecutionContext) error {
+	mode := ""
+	printable := false
+	quiet := false
+	var names []string
+
+	for _, arg := range cmd.Args {
+		switch arg {
+		case "-s", "-u":
+			mode = arg
+		case "-p":
+			printable = true
+		case "-q":
+			quiet = true
+		default:
+			if strings.HasPrefix(arg, "-") {
+				ctx.UpdateExitCode(2)
+				return fmt.Errorf("shopt: %s: invalid option", arg)
+			}
+			names = append(names, arg)
+		}
+	}
+
+	for _, name := range names {
+		if !slices.Contains(constants.ShoptOptions, name) {
+			ctx.UpdateExitCode(1)
+			return fmt.Errorf("shopt: %s: invalid shell option name", name)
+		}
+	}
+
+	if mode != "" && len(names) > 0 {
+		for _, name := range names {
+			ctx.SetOption(name, mode == "-s")
+		}
+		ctx.UpdateExitCode(0)
+		return nil
+	}
+
+	if len(names) == 0 {
+		names = constants.ShoptOptions
+	}
+
+	exitCode := 0
+	for _, name := range names {
+		enabled := ctx.IsOptionSet(name)
+		if (mode == "-s" && !enabled) || (mode == "-u" && enabled) {
+			continue
+		}
+		if !enabled && len(cmd.Args) > 0 && mode == "" && !printable {
+			exitCode = 1
+		}
+
+		switch {
+		case quiet:
+		case printable && enabled:
+			fmt.Fprintf(s.stdout, "shopt -s %s\n", name)
+		case printable:
+			fmt.Fprintf(s.stdout, "shopt -u %s\n", name)
+		case enabled:
+			fmt.Fprintf(s.stdout, "%-15s\ton\n", name)
+		default:
+			fmt.Fprintf(s.stdout, "%-15s\toff\n", name)
+		}
+	}
+
+	ctx.UpdateExitCode(exitCode)
+	return nil
+}
+
+// executeSet выполняет команду set
+func (s *CommandService) executeSet(cmd *domain.Command, ctx *domain.ExecutionContext) error {
+	args := cmd.Args
+	if len(args) == 0 {
+		s.printVariables(ctx)
+		ctx.UpdateExitCode(0)
+		return nil
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		// Оставшиеся аргументы после -- или первого не-флага становятся
+		// позиционными параметрами; set - без аргументов их не меняет
+		if arg == "--" || arg == "-" {
+			if arg == "--" || i+1 < len(args) {
+				ctx.SetPositionalArgs(slices.Clone(args[i+1:]))
+			}
+			break
+		}
+		if len(arg) < 2 || (arg[0] != '-' && arg[0] != '+') {
+			ctx.SetPositionalArgs(slices.Clone(args[i:]))
+			break
+		}
+		enabled := arg[0] == '-'
+
+		if arg[1:] == "o" {
+			if i+1 >= len(args) {
+				s.printSetOptions(ctx, enabled)
+				continue
+			}
+			i++
+			if !slices.Contains(constants.SetOptions, args[i]) {
+				ctx.UpdateExitCode(2)
+				return fmt.Errorf("set: %s: invalid option name", args[i])
+			}
+			setOption(ctx, args[i], enabled)
+			continue
+		}
+
+		for j := 1; j < len(arg); j++ {
+			name, ok := constants.SetOptionFlags[arg[j]]
+			if !ok {
+				ctx.UpdateExitCode(2)
+				return fmt.Errorf("set: %c%c: invalid option", arg[0], arg[j])
+			}
+			setOption(ctx, name, enabled)
+		}
+	}
+
+	ctx.UpdateExitCode(0)
+	return nil
+}
+
+// executeShift выполняет команду shift [n]
+func (s *CommandService) executeShift(cmd *domain.Command, ctx *domain.ExecutionContext) error {
+	n := 1
+	if len(cmd.Args) > 0 {
+		value, err := strconv.Atoi(cmd.Args[0])
+		if err != nil {
+			ctx.UpdateExitCode(1)
+			return fmt.Errorf("shift: %s: numeric argument required", cmd.Args[0])
+		}
+		if value < 0 {
+			ctx.UpdateExitCode(1)
+			return fmt.Errorf("shift: %s: shift count out of range", cmd.Args[0])
+		}
+		n = value
+	}
+
+	// Как и в bash, сдвиг больше числа параметров не меняет их и возвращает 1
+	if !ctx.ShiftPositional(n) {
+		ctx.UpdateExitCode(1)
+		return nil
+	}
+
+	ctx.UpdateExitCode(0)
+	return nil
+}
+
+// setOption включает или выключает опцию set; включение режима
+// редактирования emacs или vi выключает другой
+func setOption(ctx *domain.ExecutionContext, name string, enabled bool) {
+	if enabled && slices.Contains(constants.EditingModeOptions, name) {
+		for _, mode := range constants.EditingModeOptions {
+			ctx.SetOption(mode, false)
+		}
+	}
+	ctx.SetOption(name, enabled)
+}
+
+// printSetOptions выводит состояние опций set -o; формат +o можно повторно выполнить
+func (s *CommandService) printSetOptions(ctx *domain.ExecutionContext, human bool) {
+	for _, name := range constants.SetOptions {
+		enabled := ctx.IsOptionSet(name)
+		switch {
+		case human && enabled:
+			fmt.Fprintf(s.stdout, "%-15s\ton\n", name)
+		case human:
+			fmt.Fprintf(s.stdout, "%-15s\toff\n", name)
+		case enabled:
+			fmt.Fprintf(s.stdout, "set -o %s\n", name)
+		default:
+			fmt.Fprintf(s.stdout, "set +o %s\n", name)
+		}
+	}
+}
+
+// isValidAliasName проверяет имя псевдонима
+func isValidAliasName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, " \t\n/$`=|&;()<>'\"\\")
+}
+
+// isValidName проверяет, является ли строка допустимым именем переменной
+func isValidName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, ch := range name {
+		if ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (i > 0 && ch >= '0' && ch <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
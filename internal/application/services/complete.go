@@ -0,0 +1,497 @@
+package services
+
+import (
+	"fmt"
+	"minishell/internal/application/ports"
+	"minishell/internal/domain"
+	"minishell/pkg/constants"
+	"minishell/pkg/utils"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// completionActionFlags - однобуквенные флаги complete и compgen
+// и соответствующие им действия -A
+var completionActionFlags = []struct {
+	flag   byte
+	action string
+}{
+	{'a', constants.CompActionAlias},
+	{'b', constants.CompActionBuiltin},
+	{'c', constants.CompActionCommand},
+	{'d', constants.CompActionDirectory},
+	{'e', constants.CompActionExport},
+	{'f', constants.CompActionFile},
+	{'v', constants.CompActionVariable},
+}
+
+// completeOptions - флаги команд complete и compgen
+type completeOptions struct {
+	spec   *domain.CompletionSpec
+	print  bool
+	remove bool
+}
+
+// isEmpty проверяет, что спецификация не задана ни одним флагом
+func (o *completeOptions) isEmpty() bool {
+	spec := o.spec
+	return len(spec.Actions) == 0 && len(spec.Options) == 0 && spec.WordList == "" &&
+		spec.Function == "" && spec.Prefix == "" && spec.Suffix == "" && spec.Filter == ""
+}
+
+// executeComplete выполняет команду complete: задает спецификацию
+// дополнения аргументов команд, -p выводит спецификации, -r удаляет их
+func (s *CommandService) executeComplete(cmd *domain.Command, ctx *domain.ExecutionContext) error {
+	opts, names, err := parseCompleteOptions(cmd.Name, cmd.Args, "abcdefvAoWFPSXpr")
+	if err != nil {
+		ctx.UpdateExitCode(2)
+		return err
+	}
+
+	switch {
+	case opts.remove:
+		if len(names) == 0 {
+			ctx.ClearCompletionSpecs()
+		}
+		for _, name := range names {
+			if !ctx.UnsetCompletionSpec(name) {
+				ctx.UpdateExitCode(1)
+				return fmt.Errorf("complete: %s: no completion specification", name)
+			}
+		}
+	case opts.print || (len(names) == 0 && opts.isEmpty()):
+		return s.printCompletionSpecs(names, ctx)
+	case len(names) == 0:
+		ctx.UpdateExitCode(2)
+		return fmt.Errorf("complete: usage: complete [-abcdefvpr] [-o option] [-A action] [-W wordlist] [-F function] [-P prefix] [-S suffix] [-X filterpat] [name ...]")
+	default:
+		for _, name := range names {
+			ctx.SetCompletionSpec(name, opts.spec)
+		}
+	}
+
+	ctx.UpdateExitCode(0)
+	return nil
+}
+
+// executeCompgen выполняет команду compgen [options] [word]: выводит
+// варианты дополнения слова по флагам, как у complete
+func (s *CommandService) executeCompgen(cmd *domain.Command, ctx *domain.ExecutionContext) error {
+	opts, args, err := parseCompleteOptions(cmd.Name, cmd.Args, "abcdefvAoWFPSX")
+	if err != nil {
+		ctx.UpdateExitCode(2)
+		return err
+	}
+
+	word := ""
+	if len(args) > 0 {
+		word = args[0]
+	}
+
+	generator := &completionGenerator{system: s.system, expander: s.expander, executor: s}
+	matches := generator.generate(opts.spec, "", word, "", ctx)
+	if len(matches) == 0 && (opts.spec.HasOption(constants.CompOptDefault) || opts.spec.HasOption(constants.CompOptBashdefault)) {
+		matches = generator.actionMatches(constants.CompActionFile, word, ctx)
+	}
+
+	for _, match := range matches {
+		fmt.Fprintln(s.stdout, match.Name)
+	}
+	if len(matches) == 0 {
+		ctx.UpdateExitCode(1)
+	} else {
+		ctx.UpdateExitCode(0)
+	}
+	return nil
+}
+
+// parseCompleteOptions разбирает флаги complete и compgen из начала
+// аргументов; allowed - допустимые для команды буквы флагов. Флаги
+// с аргументом принимают его слитно (-Wlist) или следующим словом.
+func parseCompleteOptions(command string, args []string, allowed string) (*completeOptions, []string, error) {
+	opts := &completeOptions{spec: &domain.CompletionSpec{}}
+	spec := opts.spec
+
+	for len(args) > 0 && len(args[0]) > 1 && args[0][0] == '-' {
+		arg := args[0]
+		args = args[1:]
+		if arg == "--" {
+			break
+		}
+
+		for i := 1; i < len(arg); i++ {
+			flag := arg[i]
+			if strings.IndexByte(allowed, flag) < 0 {
+				return nil, nil, fmt.Errorf("%s: -%c: invalid option", command, flag)
+			}
+
+			switch flag {
+			case 'p':
+				opts.print = true
+				continue
+			case 'r':
+				opts.remove = true
+				continue
+			}
+			if action := flagAction(flag); action != "" {
+				spec.Actions = append(spec.Actions, action)
+				continue
+			}
+
+			// Остальные флаги требуют аргумента
+			value := arg[i+1:]
+			if value == "" {
+				if len(args) == 0 {
+					return nil, nil, fmt.Errorf("%s: -%c: option requires an argument", command, flag)
+				}
+				value = args[0]
+				args = args[1:]
+			}
+
+			switch flag {
+			case 'A':
+				if !isCompletionAction(value) {
+					return nil, nil, fmt.Errorf("%s: %s: invalid action name", command, value)
+				}
+				spec.Actions = append(spec.Actions, value)
+			case 'o':
+				if !slices.Contains(constants.CompletionOptions, value) {
+					return nil, nil, fmt.Errorf("%s: %s: invalid option name", command, value)
+				}
+				spec.Options = append(spec.Options, value)
+			case 'W':
+				spec.WordList = value
+			case 'F':
+				spec.Function = value
+			case 'P':
+				spec.Prefix = value
+			case 'S':
+				spec.Suffix = value
+			case 'X':
+				spec.Filter = value
+			}
+			// Аргумент флага занимает остаток слова
+			break
+		}
+	}
+
+	return opts, args, nil
+}
+
+// flagAction возвращает действие, соответствующее однобуквенному флагу
+func flagAction(flag byte) string {
+	for _, entry := range completionActionFlags {
+		if entry.flag == flag {
+			return entry.action
+		}
+	}
+	return ""
+}
+
+// isCompletionAction проверяет имя действия complete -A
+func isCompletionAction(name string) bool {
+	for _, entry := range completionActionFlags {
+		if entry.action == name {
+			return true
+		}
+	}
+	return name == constants.CompActionFunction
+}
+
+// printCompletionSpecs выводит спецификации в виде команд complete;
+// без имен - все спецификации в алфавитном порядке
+func (s *CommandService) printCompletionSpecs(names []string, ctx *domain.ExecutionContext) error {
+	if len(names) == 0 {
+		for name := range ctx.CompletionSpecs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	for _, name := range names {
+		spec, ok := ctx.GetCompletionSpec(name)
+		if !ok {
+			ctx.UpdateExitCode(1)
+			return fmt.Errorf("complete: %s: no completion specification", name)
+		}
+		fmt.Fprintln(s.stdout, formatCompletionSpec(name, spec))
+	}
+	ctx.UpdateExitCode(0)
+	return nil
+}
+
+// formatCompletionSpec возвращает команду complete, задающую спецификацию
+func formatCompletionSpec(name string, spec *domain.CompletionSpec) string {
+	parts := []string{"complete"}
+	for _, option := range spec.Options {
+		parts = append(parts, "-o", option)
+	}
+	for _, action := range spec.Actions {
+		parts = append(parts, actionFlag(action))
+	}
+
+	for _, arg := range []struct {
+		flag  string
+		value string
+	}{
+		{"-P", spec.Prefix},
+		{"-S", spec.Suffix},
+		{"-W", spec.WordList},
+		{"-X", spec.Filter},
+	} {
+		if arg.value != "" {
+			parts = append(parts, arg.flag, utils.ShellQuote(arg.value))
+		}
+	}
+	if spec.Function != "" {
+		parts = append(parts, "-F", spec.Function)
+	}
+	return strings.Join(append(parts, name), " ")
+}
+
+// actionFlag возвращает флаг действия: однобуквенный, если он есть, иначе -A name
+func actionFlag(action string) string {
+	for _, entry := range completionActionFlags {
+		if entry.action == action {
+			return "-" + string(entry.flag)
+		}
+	}
+	return "-A " + action
+}
+
+// completionMatch - вариант, найденный по спецификации дополнения
+type completionMatch struct {
+	Name string
+	// File - имя файла: при вставке экранируется, к каталогу добавляется /
+	File  bool
+	IsDir bool
+}
+
+// completionGenerator - источник имен для дополнения: команды, файлы,
+// переменные и варианты спецификаций complete
+type completionGenerator struct {
+	system   ports.SystemRepositoryOutputPort
+	expander ports.WordExpanderOutputPort
+	executor ports.CommandInputPort
+}
+
+// generate возвращает варианты дополнения слова word по спецификации:
+// имена действий, слова списка -W и массив COMPREPLY функции -F,
+// отфильтрованные шаблоном -X и дополненные -P и -S. command - команда,
+// аргумент которой дополняется, previous - предыдущее слово.
+func (g *completionGenerator) generate(spec *domain.CompletionSpec, command, word, previous string, ctx *domain.ExecutionContext) []completionMatch {
+	var matches []completionMatch
+	for _, action := range spec.Actions {
+		matches = append(matches, g.actionMatches(action, word, ctx)...)
+	}
+	if spec.WordList != "" {
+		matches = append(matches, g.wordListMatches(spec.WordList, word, ctx)...)
+	}
+	if spec.Function != "" {
+		matches = append(matches, g.functionMatches(spec.Function, command, word, previous, ctx)...)
+	}
+	if spec.Filter != "" {
+		matches = g.filterMatches(matches, spec.Filter, word, ctx)
+	}
+
+	for i := range matches {
+		matches[i].Name = spec.Prefix + matches[i].Name + spec.Suffix
+	}
+
+	if spec.HasOption(constants.CompOptPlusdirs) || (len(matches) == 0 && spec.HasOption(constants.CompOptDirnames)) {
+		matches = append(matches, g.actionMatches(constants.CompActionDirectory, word, ctx)...)
+	}
+	return matches
+}
+
+// actionMatches возвращает имена вида action, начинающиеся с word
+func (g *completionGenerator) actionMatches(action, word string, ctx *domain.ExecutionContext) []completionMatch {
+	var names []string
+	switch action {
+	case constants.CompActionAlias:
+		names = sortedMatches(ctx.Aliases, word)
+	case constants.CompActionBuiltin:
+		for _, name := range domain.BuiltinNames() {
+			if strings.HasPrefix(name, word) {
+				names = append(names, name)
+			}
+		}
+	case constants.CompActionCommand:
+		names = g.commandNames(word, ctx)
+	case constants.CompActionFunction:
+		names = sortedMatches(ctx.Functions, word)
+	case constants.CompActionVariable:
+		names = variableNames(word, false, ctx)
+	case constants.CompActionExport:
+		names = variableNames(word, true, ctx)
+	case constants.CompActionFile, constants.CompActionDirectory:
+		dir, files := g.listFiles(word, ctx)
+		var matches []completionMatch
+		for _, file := range files {
+			if action == constants.CompActionFile || file.IsDir {
+				matches = append(matches, completionMatch{Name: dir + file.Name, File: true, IsDir: file.IsDir})
+			}
+		}
+		return matches
+	}
+
+	matches := make([]completionMatch, 0, len(names))
+	for _, name := range names {
+		matches = append(matches, completionMatch{Name: name})
+	}
+	return matches
+}
+
+// wordListMatches раскрывает список слов -W и возвращает слова,
+// начинающиеся с word
+func (g *completionGenerator) wordListMatches(wordList, word string, ctx *domain.ExecutionContext) []completionMatch {
+	expanded, err := g.expander.ExpandValue(wordList, ctx)
+	if err != nil {
+		return nil
+	}
+
+	var matches []completionMatch
+	for _, candidate := range strings.Fields(expanded) {
+		if strings.HasPrefix(candidate, word) {
+			matches = append(matches, completionMatch{Name: candidate})
+		}
+	}
+	return matches
+}
+
+// functionMatches вызывает функцию -F с аргументами command, word и previous
+// и возвращает элементы массива COMPREPLY, начинающиеся с word; код
+// завершения последней команды shell сохраняется
+func (g *completionGenerator) functionMatches(name, command, word, previous string, ctx *domain.ExecutionContext) []completionMatch {
+	if _, ok := ctx.GetFunction(name); !ok {
+		return nil
+	}
+
+	exitCode := ctx.LastExitCode
+	defer ctx.UpdateExitCode(exitCode)
+
+	// Аргументы передаются в кавычках, чтобы не раскрываться повторно
+	ctx.UnsetVariable("COMPREPLY")
+	call := domain.NewCommand(name)
+	for _, arg := range []string{command, word, previous} {
+		call.AddArg(utils.ShellQuote(arg))
+	}
+	if err := g.executor.ExecuteSingleCommand(call, ctx); err != nil {
+		return nil
+	}
+
+	var replies []string
+	if arr, ok := ctx.GetArray("COMPREPLY"); ok {
+		replies = arr.Values()
+	} else if value, ok := ctx.LookupEnv("COMPREPLY"); ok {
+		replies = []string{value}
+	}
+
+	var matches []completionMatch
+	for _, reply := range replies {
+		if strings.HasPrefix(reply, word) {
+			matches = append(matches, completionMatch{Name: reply})
+		}
+	}
+	return matches
+}
+
+// filterMatches удаляет варианты, подходящие под шаблон -X; & в шаблоне
+// заменяется дополняемым словом, ! в начале оставляет только подходящие.
+// Шаблон сопоставляется со всем вариантом, * совпадает и с /.
+func (g *completionGenerator) filterMatches(matches []completionMatch, filter, word string, ctx *domain.ExecutionContext) []completionMatch {
+	negate := strings.HasPrefix(filter, "!")
+	pattern := strings.ReplaceAll(strings.TrimPrefix(filter, "!"), "&", word)
+
+	var kept []completionMatch
+	for _, match := range matches {
+		if g.expander.MatchPattern(pattern, match.Name, ctx) == negate {
+			kept = append(kept, match)
+		}
+	}
+	return kept
+}
+
+// commandNames возвращает встроенные команды, псевдонимы, функции
+// и исполняемые файлы из каталогов PATH, начинающиеся с prefix
+func (g *completionGenerator) commandNames(prefix string, ctx *domain.ExecutionContext) []string {
+	names := make(map[string]bool)
+	for _, name := range domain.BuiltinNames() {
+		names[name] = true
+	}
+	for name := range ctx.Aliases {
+		names[name] = true
+	}
+	for name := range ctx.Functions {
+		names[name] = true
+	}
+	for _, dir := range filepath.SplitList(ctx.GetEnv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		files, err := g.system.ReadDirectory(dir)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			if file.IsExecutable && strings.HasPrefix(file.Name, prefix) {
+				names[file.Name] = true
+			}
+		}
+	}
+	return sortedMatches(names, prefix)
+}
+
+// listFiles возвращает каталог пути prefix и файлы в нем, имена которых
+// начинаются с последней части пути, в алфавитном порядке. Скрытые файлы
+// возвращаются, если имя начато с точки.
+func (g *completionGenerator) listFiles(prefix string, ctx *domain.ExecutionContext) (string, []domain.FileInfo) {
+	dir, name := splitCompletionPath(prefix)
+	files, err := g.system.ReadDirectory(completionDirectory(dir, ctx))
+	if err != nil {
+		return dir, nil
+	}
+
+	var matches []domain.FileInfo
+	for _, file := range files {
+		if strings.HasPrefix(file.Name, name) && (!strings.HasPrefix(file.Name, ".") || strings.HasPrefix(name, ".")) {
+			matches = append(matches, file)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Name < matches[j].Name })
+	return dir, matches
+}
+
+// variableNames возвращает имена переменных и массивов, начинающиеся
+// с prefix; exported - только экспортируемые
+func variableNames(prefix string, exported bool, ctx *domain.ExecutionContext) []string {
+	names := make(map[string]bool)
+	for name := range ctx.Environment {
+		names[name] = true
+	}
+	for name := range ctx.Arrays {
+		names[name] = true
+	}
+	if exported {
+		for name := range names {
+			if !ctx.HasAttribute(name, domain.AttrExported) {
+				delete(names, name)
+			}
+		}
+	}
+	return sortedMatches(names, prefix)
+}
+
+// sortedMatches возвращает ключи с префиксом prefix в алфавитном порядке
+func sortedMatches[V any](names map[string]V, prefix string) []string {
+	var matches []string
+	for name := range names {
+		if strings.HasPrefix(name, prefix) {
+			matches = append(matches, name)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"minishell/internal/application/dtos"
+	"minishell/internal/application/ports"
+	"minishell/internal/domain"
+	"minishell/pkg/constants"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// unquotedSpecialChars - символы, экранируемые обратной косой чертой
+// в имени без кавычек
+const unquotedSpecialChars = " \t\n\\'\"`$;&|()<>*?[]{}!#"
+
+// CompletionService - application service для дополнения ввода по Tab
+type CompletionService struct {
+	parser    ports.CommandParserOutputPort
+	system    ports.SystemRepositoryOutputPort
+	help      ports.CommandHelpOutputPort
+	generator *completionGenerator
+}
+
+// NewCompletionService создает новый сервис дополнения
+func NewCompletionService(
+	parser ports.CommandParserOutputPort,
+	system ports.SystemRepositoryOutputPort,
+	help ports.CommandHelpOutputPort,
+	expander ports.WordExpanderOutputPort,
+	executor ports.CommandInputPort,
+) *CompletionService {
+	return &CompletionService{
+		parser: parser,
+		system: system,
+		help:   help,
+		generator: &completionGenerator{
+			system:   system,
+			expander: expander,
+			executor: executor,
+		},
+	}
+}
+
+// Complete возвращает варианты дополнения слова перед курсором: имена
+// переменных после $, варианты спецификации complete для аргументов
+// команды, имена команд на месте команды, параметры команды из ее справки
+// для слова, начинающегося с -, и файлы в остальных случаях.
+// Курсор и начало заменяемого текста задаются смещением в байтах.
+func (s *CompletionService) Complete(line string, cursor int, ctx *domain.ExecutionContext) *dtos.CompletionDTO {
+	word := s.parser.ParseCompletionLine(line, cursor)
+
+	if start, ok := variableStart(word); ok {
+		return &dtos.CompletionDTO{
+			Start:      word.Start + start,
+			Candidates: completeVariables(word.Raw[start:], ctx),
+		}
+	}
+
+	if spec, ok := lookupCompletionSpec(word, ctx); ok {
+		return &dtos.CompletionDTO{
+			Start:      word.Start,
+			Candidates: s.completeSpec(spec, word, line, cursor, ctx),
+		}
+	}
+	return &dtos.CompletionDTO{Start: word.Start, Candidates: s.completeDefault(word, ctx)}
+}
+
+// completeDefault дополняет слово без спецификации complete: имена команд
+// на месте команды, параметры команды для слова, начинающегося с -,
+// иначе имена файлов
+func (s *CompletionService) completeDefault(word *domain.CompletionLine, ctx *domain.ExecutionContext) []dtos.CompletionCandidateDTO {
+	var candidates []dtos.CompletionCandidateDTO
+	if !word.CommandPosition && word.Quote == 0 && strings.HasPrefix(word.Prefix, "-") {
+		candidates = s.completeCommandOptions(word, ctx)
+	}
+
+	switch {
+	case len(candidates) > 0:
+	case word.CommandPosition && !strings.Contains(word.Prefix, "/"):
+		candidates = s.completeCommands(word, ctx)
+	default:
+		candidates = s.completeFiles(word, word.CommandPosition, ctx)
+	}
+	return closeQuote(candidates, word)
+}
+
+// lookupCompletionSpec находит спецификацию complete для команды,
+// аргумент которой дополняется; команда, заданная путем, ищется
+// также по последнему компоненту пути
+func lookupCompletionSpec(word *domain.CompletionLine, ctx *domain.ExecutionContext) (*domain.CompletionSpec, bool) {
+	if word.CommandPosition || word.Command == "" {
+		return nil, false
+	}
+	if spec, ok := ctx.GetCompletionSpec(word.Command); ok {
+		return spec, true
+	}
+	return ctx.GetCompletionSpec(filepath.Base(word.Command))
+}
+
+// completeSpec дополняет аргумент команды по спецификации complete. На время
+// вызова функции -F задаются COMP_WORDS, COMP_CWORD, COMP_LINE и COMP_POINT.
+// Если вариантов нет, с -o default и -o bashdefault дополнение выполняется
+// как без спецификации.
+func (s *CompletionService) completeSpec(spec *domain.CompletionSpec, word *domain.CompletionLine, line string, cursor int, ctx *domain.ExecutionContext) []dtos.CompletionCandidateDTO {
+	previous := ""
+	if word.Current > 0 {
+		previous = word.Words[word.Current-1]
+	}
+
+	ctx.SetArray("COMP_WORDS", domain.NewIndexedArray(word.Words...))
+	ctx.SetEnv("COMP_CWORD", strconv.Itoa(word.Current))
+	ctx.SetEnv("COMP_LINE", line)
+	ctx.SetEnv("COMP_POINT", strconv.Itoa(cursor))
+	matches := s.generator.generate(spec, word.Command, word.Prefix, previous, ctx)
+	for _, name := range []string{"COMP_WORDS", "COMP_CWORD", "COMP_LINE", "COMP_POINT"} {
+		ctx.UnsetVariable(name)
+	}
+
+	if len(matches) == 0 && (spec.HasOption(constants.CompOptDefault) || spec.HasOption(constants.CompOptBashdefault)) {
+		return s.completeDefault(word, ctx)
+	}
+
+	dir, _ := splitCompletionPath(word.Prefix)
+	rawDir := strings.LastIndexByte(word.Raw, '/') + 1
+	seen := make(map[string]bool)
+	var candidates []dtos.CompletionCandidateDTO
+
+	for _, match := range matches {
+		if seen[match.Name] {
+			continue
+		}
+		seen[match.Name] = true
+
+		candidate := dtos.CompletionCandidateDTO{
+			Value:   match.Name,
+			Display: match.Name,
+			NoSpace: spec.HasOption(constants.CompOptNospace),
+		}
+		// Имена файлов экранируются, к каталогам добавляется /
+		if match.File || spec.HasOption(constants.CompOptFilenames) {
+			name, head := match.Name, 0
+			if dir != "" && strings.HasPrefix(name, dir) {
+				name, head = name[len(dir):], rawDir
+			}
+			candidate.Value = replacementText(word, head, name)
+			candidate.Display = name
+			if match.IsDir || (!match.File && s.isDirectory(match.Name, ctx)) {
+				candidate.Value += "/"
+				candidate.Display += "/"
+				candidate.NoSpace = true
+			}
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Display < candidates[j].Display
+	})
+	return closeQuote(candidates, word)
+}
+
+// isDirectory проверяет, является ли путь из варианта дополнения каталогом
+func (s *CompletionService) isDirectory(path string, ctx *domain.ExecutionContext) bool {
+	if path == "" {
+		return false
+	}
+	_, err := s.system.ReadDirectory(completionDirectory(path, ctx))
+	return err == nil
+}
+
+// closeQuote дополняет единственный вариант в незакрытых кавычках
+// закрывающей кавычкой
+func closeQuote(candidates []dtos.CompletionCandidateDTO, word *domain.CompletionLine) []dtos.CompletionCandidateDTO {
+	if len(candidates) == 1 && !candidates[0].NoSpace && word.Quote != 0 {
+		candidates[0].Value += string(word.Quote)
+	}
+	return candidates
+}
+
+// variableStart находит в дополняемом слове начало $NAME или ${NAME,
+// которое заканчивается на курсоре
+func variableStart(word *domain.CompletionLine) (int, bool) {
+	if word.Quote == '\'' {
+		return 0, false
+	}
+
+	dollar := strings.LastIndexByte(word.Raw, '$')
+	if dollar < 0 || (dollar > 0 && word.Raw[dollar-1] == '\\') {
+		return 0, false
+	}
+	name := strings.TrimPrefix(word.Raw[dollar+1:], "{")
+	if name != "" && !isValidName(name) {
+		return 0, false
+	}
+	return dollar, true
+}
+
+// completeVariables возвращает имена переменных и массивов, начинающиеся
+// с имени после $ или ${
+func completeVariables(text string, ctx *domain.ExecutionContext) []dtos.CompletionCandidateDTO {
+	braced := strings.HasPrefix(text, "${")
+	prefix := strings.TrimPrefix(strings.TrimPrefix(text, "$"), "{")
+
+	var candidates []dtos.CompletionCandidateDTO
+	for _, name := range variableNames(prefix, false, ctx) {
+		value := "$" + name
+		if braced {
+			value = "${" + name + "}"
+		}
+		candidates = append(candidates, dtos.CompletionCandidateDTO{Value: value, Display: name})
+	}
+	return candidates
+}
+
+// completeCommands возвращает встроенные команды, псевдонимы, функции
+// и исполняемые файлы из каталогов PATH
+func (s *CompletionService) completeCommands(word *domain.CompletionLine, ctx *domain.ExecutionContext) []dtos.CompletionCandidateDTO {
+	var candidates []dtos.CompletionCandidateDTO
+	for _, name := range s.generator.commandNames(word.Prefix, ctx) {
+		candidates = append(candidates, dtos.CompletionCandidateDTO{
+			Value:   replacementText(word, 0, name),
+			Display: name,
+		})
+	}
+	return candidates
+}
+
+// completeFiles возвращает файлы и каталоги, имена которых начинаются
+// с последней части пути; на месте команды - только каталоги и
+// исполняемые файлы
+func (s *CompletionService) completeFiles(word *domain.CompletionLine, executables bool, ctx *domain.ExecutionContext) []dtos.CompletionCandidateDTO {
+	_, files := s.generator.listFiles(word.Prefix, ctx)
+
+	rawDir := strings.LastIndexByte(word.Raw, '/') + 1
+	var candidates []dtos.CompletionCandidateDTO
+	for _, file := range files {
+		if executables && !file.IsDir && !file.IsExecutable {
+			continue
+		}
+
+		candidate := dtos.CompletionCandidateDTO{
+			Value:   replacementText(word, rawDir, file.Name),
+			Display: file.Name,
+		}
+		if file.IsDir {
+			candidate.Value += "/"
+			candidate.Display += "/"
+			candidate.NoSpace = true
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+// splitCompletionPath делит дополняемый путь на каталог (вместе с
+// последней /) и начало имени файла
+func splitCompletionPath(path string) (string, string) {
+	slash := strings.LastIndexByte(path, '/')
+	return path[:slash+1], path[slash+1:]
+}
+
+// completionDirectory возвращает каталог для поиска файлов: ~ и переменные
+// в начале пути раскрываются, относительный путь отсчитывается от
+// текущей директории
+func completionDirectory(dir string, ctx *domain.ExecutionContext) string {
+	if strings.HasPrefix(dir, "~/") {
+		dir = ctx.GetEnv("HOME") + dir[1:]
+	}
+	dir = os.Expand(dir, ctx.GetEnv)
+
+	if dir == "" {
+		return ctx.CurrentDir
+	}
+	if !filepath.IsAbs(dir) {
+		return filepath.Join(ctx.CurrentDir, dir)
+	}
+	return dir
+}
+
+// replacementText возвращает текст, заменяющий слово до курсора: начало
+// слова до rawDir сохраняется как введено, имя экранируется с учетом
+// незакрытой кавычки
+func replacementText(word *domain.CompletionLine, rawDir int, name string) string {
+	head := word.Raw[:rawDir]
+	if word.Quote != 0 && word.QuoteStart >= rawDir {
+		head += string(word.Quote)
+	}
+	return head + escapeCompletion(name, word.Quote)
+}
+
+// escapeCompletion экранирует имя для вставки в строку: без кавычек -
+// обратной косой чертой перед специальными символами, в двойных
+// кавычках - перед ", $, ` и \, в одинарных - через '\”
+func escapeCompletion(name string, quote byte) string {
+	special := unquotedSpecialChars
+	switch quote {
+	case '\'':
+		return strings.ReplaceAll(name, "'", `'\''`)
+	case '"':
+		special = "\"$`\\"
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(name); i++ {
+		if strings.IndexByte(special, name[i]) >= 0 {
+			out.WriteByte('\\')
+		}
+		out.WriteByte(name[i])
+	}
+	return out.String()
+}
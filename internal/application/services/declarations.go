@@ -0,0 +1,344 @@
+package services
+
+import (
+	"fmt"
+	"minishell/internal/domain"
+	"minishell/pkg/utils"
+	"sort"
+	"strings"
+)
+
+// attributeFlags - флаги declare и соответствующие им атрибуты
+// в порядке, в котором они выводятся declare -p
+var attributeFlags = []struct {
+	flag byte
+	attr domain.VariableAttribute
+}{
+	{'i', domain.AttrInteger},
+	{'n', domain.AttrNameref},
+	{'r', domain.AttrReadonly},
+	{'x', domain.AttrExported},
+	{'l', domain.AttrLowercase},
+	{'u', domain.AttrUppercase},
+}
+
+// declareOptions - флаги команд declare, typeset, local и readonly
+type declareOptions struct {
+	add         domain.VariableAttribute
+	remove      domain.VariableAttribute
+	indexed     bool
+	associative bool
+	print       bool
+	global      bool
+}
+
+// isEmpty проверяет, что не задано ни одного флага
+func (o *declareOptions) isEmpty() bool {
+	return *o == declareOptions{}
+}
+
+// parseDeclareOptions разбирает флаги -x и +x из начала аргументов;
+// allowed - допустимые для команды буквы флагов
+func parseDeclareOptions(command string, args []string, allowed string) (*declareOptions, []string, error) {
+	opts := &declareOptions{}
+
+	for len(args) > 0 && len(args[0]) > 1 && (args[0][0] == '-' || args[0][0] == '+') {
+		arg := args[0]
+		args = args[1:]
+		if arg == "--" {
+			break
+		}
+
+		enable := arg[0] == '-'
+		for i := 1; i < len(arg); i++ {
+			flag := arg[i]
+			if strings.IndexByte(allowed, flag) < 0 {
+				return nil, nil, fmt.Errorf("%s: %c%c: invalid option", command, arg[0], flag)
+			}
+
+			switch flag {
+			case 'a', 'A':
+				if !enable {
+					return nil, nil, fmt.Errorf("%s: %c%c: cannot destroy array variables in this way", command, arg[0], flag)
+				}
+				opts.indexed = opts.indexed || flag == 'a'
+				opts.associative = opts.associative || flag == 'A'
+			case 'p':
+				opts.print = true
+			case 'g':
+				opts.global = true
+			default:
+				attr := flagAttribute(flag)
+				if enable {
+					opts.add |= attr
+					opts.remove &^= attr
+				} else {
+					opts.remove |= attr
+					opts.add &^= attr
+				}
+			}
+		}
+	}
+
+	// -l и -u взаимоисключающие: действует последний из флагов
+	if opts.add.Has(domain.AttrLowercase) && opts.add.Has(domain.AttrUppercase) {
+		opts.add &^= domain.AttrLowercase
+	}
+
+	return opts, args, nil
+}
+
+// flagAttribute возвращает атрибут, соответствующий флагу declare
+func flagAttribute(flag byte) domain.VariableAttribute {
+	for _, entry := range attributeFlags {
+		if entry.flag == flag {
+			return entry.attr
+		}
+	}
+	return 0
+}
+
+// executeDeclare выполняет команду declare/typeset: -a и -A объявляют
+// массивы, -i -l -u -n -x -r добавляют атрибуты (+ снимает их), -p выводит
+// объявления переменных, -g внутри функции создает глобальную переменную
+func (s *CommandService) executeDeclare(cmd *domain.Command, ctx *domain.ExecutionContext) error {
+	opts, args, err := parseDeclareOptions(cmd.Name, cmd.Args, "aAgilnprux")
+	if err != nil {
+		ctx.UpdateExitCode(2)
+		return err
+	}
+
+	switch {
+	case len(args) == 0 && opts.isEmpty():
+		s.printVariables(ctx)
+		ctx.UpdateExitCode(0)
+	case len(args) == 0:
+		s.printDeclarations(opts, ctx)
+		ctx.UpdateExitCode(0)
+	case opts.print:
+		ctx.UpdateExitCode(s.printNamedDeclarations(cmd.Name, args, ctx))
+	default:
+		ctx.UpdateExitCode(s.declareVariables(cmd.Name, args, opts, ctx))
+	}
+	return nil
+}
+
+// executeReadonly выполняет команду readonly [-p] [-a|-A] [name[=value] ...]
+func (s *CommandService) executeReadonly(cmd *domain.Command, ctx *domain.ExecutionContext) error {
+	opts, args, err := parseDeclareOptions(cmd.Name, cmd.Args, "aAp")
+	if err != nil {
+		ctx.UpdateExitCode(2)
+		return err
+	}
+	opts.add |= domain.AttrReadonly
+	opts.global = true
+
+	if len(args) == 0 || opts.print {
+		s.printDeclarations(&declareOptions{add: domain.AttrReadonly}, ctx)
+		ctx.UpdateExitCode(0)
+		return nil
+	}
+
+	ctx.UpdateExitCode(s.declareVariables(cmd.Name, args, opts, ctx))
+	return nil
+}
+
+// declareVariables объявляет переменные с атрибутами и значениями;
+// ошибки выводятся для каждого аргумента отдельно, возвращается код завершения
+func (s *CommandService) declareVariables(command string, args []string, opts *declareOptions, ctx *domain.ExecutionContext) int {
+	exitCode := 0
+	for _, arg := range args {
+		if err := s.declareVariable(arg, opts, ctx); err != nil {
+			s.presenter.ShowError(fmt.Sprintf("%s: %s", command, err))
+			exitCode = 1
+		}
+	}
+	return exitCode
+}
+
+// declareVariable объявляет одну переменную name или name=value
+func (s *CommandService) declareVariable(arg string, opts *declareOptions, ctx *domain.ExecutionContext) error {
+	assignment, err := s.parser.ParseAssignment(arg)
+	if err != nil {
+		return err
+	}
+
+	name := arg
+	if assignment != nil {
+		name = assignment.Name
+	}
+	if !isValidName(name) {
+		return fmt.Errorf("`%s': not a valid identifier", arg)
+	}
+
+	// Локальная переменная не может перекрыть readonly переменную
+	if !opts.global && ctx.CurrentFrame() != nil {
+		if ctx.IsReadonly(name) {
+			return fmt.Errorf("%s: readonly variable", name)
+		}
+		ctx.DeclareLocal(name)
+	}
+
+	// Флаг -n меняет саму ссылку, остальные - переменную, на которую она указывает
+	nameref := opts.add.Has(domain.AttrNameref) || opts.remove.Has(domain.AttrNameref)
+	if !nameref {
+		name = ctx.ResolveName(name)
+	}
+
+	if ctx.IsReadonly(name) && (assignment != nil || opts.remove != 0) {
+		return fmt.Errorf("%s: readonly variable", name)
+	}
+
+	arr, isArray := ctx.GetArray(name)
+	switch {
+	case opts.associative && isArray && !arr.Associative:
+		return fmt.Errorf("%s: cannot convert indexed to associative array", name)
+	case opts.indexed && isArray && arr.Associative:
+		return fmt.Errorf("%s: cannot convert associative to indexed array", name)
+	case opts.associative && !isArray:
+		ctx.SetArray(name, domain.NewAssociativeArray())
+	case opts.indexed && !isArray:
+		ctx.ConvertToArray(name)
+	}
+
+	if opts.add.Has(domain.AttrNameref) {
+		if err := s.declareNameref(name, assignment, ctx); err != nil {
+			return err
+		}
+	}
+
+	// Атрибуты значения применяются до присваивания, readonly - после
+	attrs := opts.add &^ (domain.AttrReadonly | domain.AttrNameref)
+	if attrs.Has(domain.AttrLowercase) {
+		ctx.SetAttribute(name, domain.AttrUppercase, false)
+	}
+	if attrs.Has(domain.AttrUppercase) {
+		ctx.SetAttribute(name, domain.AttrLowercase, false)
+	}
+	ctx.SetAttribute(name, attrs, true)
+	ctx.SetAttribute(name, opts.remove, false)
+
+	if assignment != nil && !opts.add.Has(domain.AttrNameref) {
+		if err := s.assign(assignment.WithName(name), ctx); err != nil {
+			return err
+		}
+	}
+
+	ctx.SetAttribute(name, opts.add&domain.AttrReadonly, true)
+	return nil
+}
+
+// declareNameref делает переменную ссылкой declare -n на другую переменную
+func (s *CommandService) declareNameref(name string, assignment *domain.Assignment, ctx *domain.ExecutionContext) error {
+	if _, isArray := ctx.GetArray(name); isArray {
+		return fmt.Errorf("%s: reference variable cannot be an array", name)
+	}
+
+	target := ctx.GetEnv(name)
+	if assignment != nil {
+		if assignment.Compound || assignment.HasSubscript() {
+			return fmt.Errorf("%s: reference variable cannot be an array", name)
+		}
+		value, err := s.expander.ExpandValue(assignment.Value, ctx)
+		if err != nil {
+			return err
+		}
+		target = value
+	}
+
+	if target != "" {
+		if !isValidName(target) {
+			return fmt.Errorf("`%s': invalid variable name for name reference", target)
+		}
+		if target == name {
+			return fmt.Errorf("%s: nameref variable self references not allowed", name)
+		}
+		ctx.SetEnv(name, target)
+	}
+
+	ctx.SetAttribute(name, domain.AttrNameref, true)
+	return nil
+}
+
+// printDeclarations выводит в виде команд declare переменные, у которых
+// есть все атрибуты из opts; с -a и -A выводятся только массивы
+func (s *CommandService) printDeclarations(opts *declareOptions, ctx *domain.ExecutionContext) {
+	for _, name := range declaredNames(ctx) {
+		if !ctx.Attributes[name].Has(opts.add) {
+			continue
+		}
+		arr, isArray := ctx.GetArray(name)
+		if (opts.indexed && (!isArray || arr.Associative)) || (opts.associative && (!isArray || !arr.Associative)) {
+			continue
+		}
+		fmt.Fprintln(s.stdout, formatDeclaration(name, ctx))
+	}
+}
+
+// printNamedDeclarations выводит объявления заданных переменных для declare -p
+func (s *CommandService) printNamedDeclarations(command string, names []string, ctx *domain.ExecutionContext) int {
+	exitCode := 0
+	for _, name := range names {
+		if !ctx.IsVariableSet(name) && ctx.Attributes[name] == 0 {
+			s.presenter.ShowError(fmt.Sprintf("%s: %s: not found", command, name))
+			exitCode = 1
+			continue
+		}
+		fmt.Fprintln(s.stdout, formatDeclaration(name, ctx))
+	}
+	return exitCode
+}
+
+// declaredNames возвращает отсортированные имена всех переменных,
+// включая объявленные без значения
+func declaredNames(ctx *domain.ExecutionContext) []string {
+	seen := make(map[string]bool)
+	for name := range ctx.Environment {
+		seen[name] = true
+	}
+	for name := range ctx.Arrays {
+		seen[name] = true
+	}
+	for name := range ctx.Attributes {
+		seen[name] = true
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		if isValidName(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatDeclaration форматирует переменную как команду declare
+func formatDeclaration(name string, ctx *domain.ExecutionContext) string {
+	var flags strings.Builder
+	arr, isArray := ctx.GetArray(name)
+	if isArray {
+		if arr.Associative {
+			flags.WriteByte('A')
+		} else {
+			flags.WriteByte('a')
+		}
+	}
+	for _, entry := range attributeFlags {
+		if ctx.HasAttribute(name, entry.attr) {
+			flags.WriteByte(entry.flag)
+		}
+	}
+	if flags.Len() == 0 {
+		flags.WriteByte('-')
+	}
+
+	declaration := "declare -" + flags.String() + " " + name
+	if isArray {
+		return declaration + "=" + formatArrayElements(arr)
+	}
+	if value, ok := ctx.LookupEnv(name); ok {
+		return declaration + "=" + utils.ShellQuote(value)
+	}
+	return declaration
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"minishell/internal/application/dtos"
+	"minishell/internal/application/ports"
+	"minishell/internal/domain"
+	"minishell/pkg/constants"
+	"strings"
+)
+
+// defaultHighlightColors - цвета подсветки по умолчанию (параметры SGR)
+var defaultHighlightColors = map[domain.HighlightKind]string{
+	domain.HighlightCommand:     "1;32",
+	domain.HighlightError:       "31",
+	domain.HighlightKeyword:     "1;34",
+	domain.HighlightString:      "33",
+	domain.HighlightVariable:    "36",
+	domain.HighlightOperator:    "35",
+	domain.HighlightRedirection: "35",
+	domain.HighlightComment:     "90",
+}
+
+// HighlightService - application service для подсветки синтаксиса
+// вводимой строки
+type HighlightService struct {
+	parser ports.CommandParserOutputPort
+	system ports.SystemRepositoryOutputPort
+}
+
+// NewHighlightService создает новый сервис подсветки
+func NewHighlightService(
+	parser ports.CommandParserOutputPort,
+	system ports.SystemRepositoryOutputPort,
+) *HighlightService {
+	return &HighlightService{
+		parser: parser,
+		system: system,
+	}
+}
+
+// Highlight возвращает фрагменты строки с цветами из схемы MINISHELL_COLORS.
+// Имя команды, которой нет среди встроенных команд, псевдонимов, функций
+// и исполняемых файлов PATH, выводится цветом ошибки.
+func (s *HighlightService) Highlight(line string, ctx *domain.ExecutionContext) []dtos.HighlightDTO {
+	colors, ok := highlightColors(ctx)
+	if !ok {
+		return nil
+	}
+
+	var result []dtos.HighlightDTO
+	for _, span := range s.parser.ParseHighlightLine(line) {
+		kind := span.Kind
+		if kind == domain.HighlightCommand && !s.commandExists(span.Text, ctx) {
+			kind = domain.HighlightError
+		}
+		if style := colors[kind]; style != "" {
+			result = append(result, dtos.HighlightDTO{Start: span.Start, End: span.End, Style: style})
+		}
+	}
+	return result
+}
+
+// commandExists проверяет, можно ли выполнить команду с таким именем
+func (s *HighlightService) commandExists(name string, ctx *domain.ExecutionContext) bool {
+	if name == "" {
+		return false
+	}
+	if domain.NewCommand(name).IsBuiltin() {
+		return true
+	}
+	if _, ok := ctx.GetAlias(name); ok {
+		return true
+	}
+	if _, ok := ctx.GetFunction(name); ok {
+		return true
+	}
+	_, _, ok := findExecutable(s.system, name, ctx)
+	return ok
+}
+
+// highlightColors возвращает схему цветов: значения по умолчанию,
+// измененные записями вида kind=SGR через : в MINISHELL_COLORS. Пустое
+// значение отключает цвет фрагмента, none - всю подсветку.
+func highlightColors(ctx *domain.ExecutionContext) (map[domain.HighlightKind]string, bool) {
+	scheme := ctx.GetEnv(constants.HighlightColorsVar)
+	if scheme == constants.HighlightNone {
+		return nil, false
+	}
+
+	colors := make(map[domain.HighlightKind]string, len(defaultHighlightColors))
+	for kind, style := range defaultHighlightColors {
+		colors[kind] = style
+	}
+	for _, entry := range strings.Split(scheme, ":") {
+		kind, style, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		if _, known := colors[domain.HighlightKind(kind)]; known && isSGRStyle(style) {
+			colors[domain.HighlightKind(kind)] = style
+		}
+	}
+	return colors, true
+}
+
+// isSGRStyle проверяет, что стиль состоит только из чисел через ;
+func isSGRStyle(style string) bool {
+	for i := 0; i < len(style); i++ {
+		if style[i] != ';' && (style[i] < '0' || style[i] > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"fmt"
+	"minishell/internal/application/ports"
+	"minishell/internal/domain"
+	"minishell/pkg/constants"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoadHistory включает историю интерактивного shell: задает значения
+// HISTFILE, HISTSIZE и HISTFILESIZE по умолчанию и читает файл истории
+func (s *ShellService) LoadHistory(ctx *domain.ExecutionContext) {
+	if _, ok := ctx.LookupEnv("HISTFILE"); !ok {
+		if home := ctx.GetEnv("HOME"); home != "" {
+			ctx.SetEnv("HISTFILE", filepath.Join(home, constants.DefaultHistoryFile))
+		}
+	}
+	for _, name := range []string{"HISTSIZE", "HISTFILESIZE"} {
+		if _, ok := ctx.LookupEnv(name); !ok {
+			ctx.SetEnv(name, strconv.Itoa(constants.DefaultHistorySize))
+		}
+	}
+	ctx.SetOption(constants.OptHistory, true)
+
+	if path := historyFile(ctx); path != "" {
+		// Файла истории может еще не быть - это не ошибка
+		readHistoryFile(s.system, path, ctx)
+	}
+}
+
+// SaveHistory записывает историю в HISTFILE, оставляя в файле не более
+// HISTFILESIZE последних записей
+func (s *ShellService) SaveHistory(ctx *domain.ExecutionContext) {
+	if !ctx.IsOptionSet(constants.OptHistory) {
+		return
+	}
+	if path := historyFile(ctx); path != "" {
+		if err := writeHistoryFile(s.system, path, ctx); err != nil {
+			s.presenter.ShowError(fmt.Sprintf("history: %s: cannot write history file: %v", path, err))
+		}
+	}
+}
+
+// addHistory добавляет введенную команду в историю и ограничивает ее размер HISTSIZE
+func addHistory(input string, ctx *domain.ExecutionContext) {
+	if strings.TrimSpace(input) == "" {
+		return
+	}
+	ctx.History.AddAt(input, ctx.CurrentDir, time.Now().Unix())
+	ctx.History.Truncate(historyLimit(ctx, "HISTSIZE"))
+}
+
+// SuggestHistory возвращает запись истории, которая продолжает введенную
+// строку, для подсказки при вводе; предпочтительнее команды, выполненные
+// в текущем каталоге
+func (s *ShellService) SuggestHistory(line string, ctx *domain.ExecutionContext) string {
+	if line == "" {
+		return ""
+	}
+	suggestion, _ := ctx.History.Suggest(line, ctx.CurrentDir)
+	return suggestion
+}
+
+// executeHistory выполняет команду history [n], history -c, history -d N,
+// history -w [file] и history -r [file]
+func (s *CommandService) executeHistory(cmd *domain.Command, ctx *domain.ExecutionContext) error {
+	args := cmd.Args
+	if len(args) == 0 || len(args[0]) < 2 || args[0][0] != '-' {
+		return s.printHistory(args, ctx)
+	}
+
+	for len(args) > 0 {
+		arg := args[0]
+		args = args[1:]
+
+		switch arg {
+		case "-c":
+			ctx.History.Clear()
+		case "-d":
+			if len(args) == 0 {
+				ctx.UpdateExitCode(2)
+				return fmt.Errorf("history: -d: option requires an argument")
+			}
+			if err := deleteHistoryEntry(args[0], ctx); err != nil {
+				ctx.UpdateExitCode(1)
+				return err
+			}
+			args = args[1:]
+		case "-w", "-r":
+			path := historyFile(ctx)
+			if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+				path = args[0]
+				args = args[1:]
+			}
+			if path == "" {
+				ctx.UpdateExitCode(1)
+				return fmt.Errorf("history: HISTFILE not set")
+			}
+
+			var err error
+			if arg == "-w" {
+				err = writeHistoryFile(s.system, path, ctx)
+			} else {
+				err = readHistoryFile(s.system, path, ctx)
+			}
+			if err != nil {
+				ctx.UpdateExitCode(1)
+				return fmt.Errorf("history: %w", err)
+			}
+		default:
+			ctx.UpdateExitCode(2)
+			return fmt.Errorf("history: %s: invalid option", arg)
+		}
+	}
+
+	ctx.UpdateExitCode(0)
+	return nil
+}
+
+// printHistory выводит историю с номерами записей; history n - только n последних
+func (s *CommandService) printHistory(args []string, ctx *domain.ExecutionContext) error {
+	entries := ctx.History.Entries()
+	start := 0
+
+	switch len(args) {
+	case 0:
+	case 1:
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 0 {
+			ctx.UpdateExitCode(1)
+			return fmt.Errorf("history: %s: numeric argument required", args[0])
+		}
+		start = max(len(entries)-n, 0)
+	default:
+		ctx.UpdateExitCode(1)
+		return fmt.Errorf("history: too many arguments")
+	}
+
+	for i := start; i < len(entries); i++ {
+		fmt.Fprintf(s.stdout, "%5d  %s\n", ctx.History.Base()+i, entries[i])
+	}
+	ctx.UpdateExitCode(0)
+	return nil
+}
+
+// deleteHistoryEntry удаляет запись history -d N; отрицательный номер
+// отсчитывается от конца истории
+func deleteHistoryEntry(arg string, ctx *domain.ExecutionContext) error {
+	number, err := strconv.Atoi(arg)
+	if err == nil && number < 0 {
+		number += ctx.History.Base() + ctx.History.Len()
+	}
+	if err != nil || !ctx.History.Delete(number) {
+		return fmt.Errorf("history: %s: history position out of range", arg)
+	}
+	return nil
+}
+
+// historyFile возвращает путь к файлу истории из HISTFILE
+func historyFile(ctx *domain.ExecutionContext) string {
+	return ctx.GetEnv("HISTFILE")
+}
+
+// historyLimit возвращает ограничение из переменной HISTSIZE или HISTFILESIZE;
+// -1, если переменная не задана, не число или отрицательна
+func historyLimit(ctx *domain.ExecutionContext, name string) int {
+	limit, err := strconv.Atoi(ctx.GetEnv(name))
+	if err != nil || limit < 0 {
+		return -1
+	}
+	return limit
+}
+
+// readHistoryFile добавляет в историю записи файла path. Если файл
+// начинается со строки #<время>, как пишет writeHistoryFile, запись - это
+// все строки до следующей такой метки, иначе каждая строка - отдельная запись.
+func readHistoryFile(system ports.SystemRepositoryOutputPort, path string, ctx *domain.ExecutionContext) error {
+	data, err := system.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if _, ok := historyTimestamp(lines[0]); !ok {
+		for _, line := range lines {
+			if line != "" {
+				ctx.History.Add(line)
+			}
+		}
+		ctx.History.Truncate(historyLimit(ctx, "HISTSIZE"))
+		return nil
+	}
+
+	var entry []string
+	var entryTime int64
+	flush := func() {
+		if len(entry) > 0 {
+			ctx.History.AddAt(strings.Join(entry, "\n"), "", entryTime)
+		}
+		entry = nil
+	}
+	for _, line := range lines {
+		if timestamp, ok := historyTimestamp(line); ok {
+			flush()
+			entryTime = timestamp
+			continue
+		}
+		entry = append(entry, line)
+	}
+	flush()
+
+	ctx.History.Truncate(historyLimit(ctx, "HISTSIZE"))
+	return nil
+}
+
+// writeHistoryFile записывает историю в файл path, оставляя не более
+// HISTFILESIZE последних записей. Перед каждой записью, как в bash,
+// пишется метка #<время>, поэтому многострочные команды (тела функций,
+// строки в кавычках) читаются обратно целиком.
+func writeHistoryFile(system ports.SystemRepositoryOutputPort, path string, ctx *domain.ExecutionContext) error {
+	entries, times := ctx.History.Entries(), ctx.History.Times()
+	if limit := historyLimit(ctx, "HISTFILESIZE"); limit >= 0 && len(entries) > limit {
+		entries, times = entries[len(entries)-limit:], times[len(times)-limit:]
+	}
+
+	now := time.Now().Unix()
+	var data strings.Builder
+	for i, entry := range entries {
+		timestamp := times[i]
+		if timestamp == 0 {
+			timestamp = now
+		}
+		fmt.Fprintf(&data, "#%d\n%s\n", timestamp, entry)
+	}
+	// Команды могут содержать пароли, поэтому файл доступен только владельцу
+	return system.WritePrivateFile(path, []byte(data.String()))
+}
+
+// historyTimestamp разбирает строку-метку времени записи вида #1700000000
+func historyTimestamp(line string) (int64, bool) {
+	if len(line) < 2 || line[0] != '#' {
+		return 0, false
+	}
+	timestamp, err := strconv.ParseInt(line[1:], 10, 64)
+	return timestamp, err == nil && line[1] != '+' && line[1] != '-'
+}
@@ -1,8 +1,10 @@
 package services
 
 import (
+	"errors"
 	"minishell/internal/application/ports"
 	"minishell/internal/domain"
+	"minishell/pkg/constants"
 )
 
 // ShellService - application service для операций shell
@@ -30,37 +32,47 @@ func NewShellService(
 
 // ExecuteCommand выполняет команду
 func (s *ShellService) ExecuteCommand(input string, ctx *domain.ExecutionContext) error {
+	// Подстановка из истории выполняется до разбора; раскрытая строка
+	// выводится в поток ошибок, чтобы было видно, какая команда выполняется
+	if ctx.IsOptionSet(constants.OptHistexpand) {
+		expanded, changed, err := s.parser.ExpandHistory(input, ctx.History)
+		if err != nil {
+			s.presenter.ShowError(err.Error())
+			ctx.UpdateExitCode(1)
+			return nil
+		}
+		if changed {
+			s.presenter.ShowMessage(expanded)
+			input = expanded
+		}
+	}
+
+	if ctx.IsOptionSet(constants.OptHistory) {
+		addHistory(input, ctx)
+	}
+
 	if input == "exit" {
 		ctx.Stop()
 		return nil
 	}
 
-	pipelines, err := s.parser.Parse(input, ctx.Environment)
+	pipelines, err := s.parser.Parse(input, ctx.Aliases)
 	if err != nil {
 		s.presenter.ShowError("parse error: " + err.Error())
 		ctx.UpdateExitCode(1)
 		return err
 	}
 
-	// Сохраняем исходный exit code для правильной работы логических операторов
-	originalExitCode := ctx.LastExitCode
-
-	for i, pipeline := range pipelines {
-		// Для первого пайплайна всегда выполняем, для остальных - проверяем оператор
-		if i > 0 {
-			if !pipeline.ShouldContinueExecution(originalExitCode) {
-				// Пропускаем выполнение этого пайплайна
-				continue
-			}
-		}
+	if err := s.executor.ExecutePipelines(pipelines, ctx); err != nil {
+		s.presenter.ShowError("execution error: " + err.Error())
+		ctx.UpdateExitCode(1)
 
-		if err := s.executor.ExecutePipeline(pipeline, ctx); err != nil {
-			s.presenter.ShowError("execution error: " + err.Error())
-			ctx.UpdateExitCode(1)
+		// Неинтерактивный shell завершается после ошибки раскрытия параметра
+		var expansionErr *domain.ExpansionError
+		if errors.As(err, &expansionErr) && !ctx.Interactive {
+			ctx.UpdateExitCode(127)
+			return err
 		}
-
-		// Обновляем originalExitCode после каждого выполненного пайплайна
-		originalExitCode = ctx.LastExitCode
 	}
 
 	// Обновляем текущую директорию после выполнения команд
@@ -71,6 +83,37 @@ func (s *ShellService) ExecuteCommand(input string, ctx *domain.ExecutionContext
 	return nil
 }
 
+// IsInputComplete проверяет, завершен ли ввод или нужна строка продолжения
+func (s *ShellService) IsInputComplete(input string) bool {
+	return s.parser.IsComplete(input)
+}
+
+// ReadCommand читает одну законченную команду неинтерактивного ввода
+// (см. readCompleteCommand)
+func (s *ShellService) ReadCommand(nextLine func() (string, error)) (string, error) {
+	return readCompleteCommand(nextLine, s.parser)
+}
+
+// readCompleteCommand собирает из строк nextLine одну законченную команду:
+// строки продолжения добавляются, пока ввод не завершен (открытая кавычка,
+// тело функции). Ошибка чтения возвращается, только если команда еще не
+// начата; незавершенная команда в конце ввода возвращается как есть, и ее
+// разбор сообщает о синтаксической ошибке.
+func readCompleteCommand(nextLine func() (string, error), parser ports.CommandParserOutputPort) (string, error) {
+	command, err := nextLine()
+	if err != nil {
+		return "", err
+	}
+	for !parser.IsComplete(command) {
+		line, err := nextLine()
+		if err != nil {
+			break
+		}
+		command += "\n" + line
+	}
+	return command, nil
+}
+
 // ShouldContinue проверяет должен ли shell продолжать работу
 func (s *ShellService) ShouldContinue(ctx *domain.ExecutionContext) bool {
 	return ctx.IsRunning
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"minishell/internal/domain"
+	"minishell/pkg/constants"
+	"path/filepath"
+	"strings"
+)
+
+// executeSource выполняет команду source file [args] (или . file [args]):
+// команды файла выполняются в текущем shell, поэтому меняют его переменные,
+// функции и текущую директорию. Аргументы становятся позиционными параметрами
+// на время выполнения файла, return завершает только сам файл.
+func (s *CommandService) executeSource(cmd *domain.Command, ctx *domain.ExecutionContext) error {
+	if len(cmd.Args) == 0 {
+		ctx.UpdateExitCode(2)
+		return fmt.Errorf("%s: filename argument required", cmd.Name)
+	}
+	if ctx.SourceDepth >= constants.MaxSourceDepth {
+		ctx.UpdateExitCode(1)
+		return fmt.Errorf("%s: %s: maximum source nesting level exceeded (%d)", cmd.Name, cmd.Args[0], constants.MaxSourceDepth)
+	}
+
+	data, err := s.readSourceFile(cmd.Args[0], ctx)
+	if err != nil {
+		ctx.UpdateExitCode(1)
+		return fmt.Errorf("%s: %w", cmd.Name, err)
+	}
+
+	if len(cmd.Args) > 1 {
+		ctx.PushPositional(cmd.Args[1:])
+		defer ctx.PopPositional()
+	}
+
+	ctx.SourceDepth++
+	defer func() { ctx.SourceDepth-- }()
+
+	ctx.UpdateExitCode(0)
+	err = s.executeScript(string(data), ctx)
+
+	// return завершает только прочитанный файл
+	ctx.IsReturning = false
+	if err != nil {
+		ctx.UpdateExitCode(1)
+		return fmt.Errorf("%s: %w", cmd.Args[0], err)
+	}
+	return nil
+}
+
+// readSourceFile читает файл для source; имя без / ищется в каталогах PATH,
+// а затем в текущей директории
+func (s *CommandService) readSourceFile(name string, ctx *domain.ExecutionContext) ([]byte, error) {
+	if !strings.Contains(name, "/") {
+		for _, dir := range filepath.SplitList(ctx.GetEnv("PATH")) {
+			if dir == "" {
+				continue
+			}
+			if data, err := s.system.ReadFile(filepath.Join(dir, name)); err == nil {
+				return data, nil
+			}
+		}
+	}
+
+	data, err := s.system.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s: file not found", name)
+	}
+	if err != nil {
+		// Сообщается только причина: is a directory, permission denied
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			err = pathErr.Err
+		}
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return data, nil
+}
+
+// executeScript выполняет текст скрипта по одной законченной команде, чтобы
+// псевдонимы, определенные в скрипте, действовали на следующие строки
+func (s *CommandService) executeScript(script string, ctx *domain.ExecutionContext) error {
+	lines := strings.Split(script, "\n")
+	nextLine := func() (string, error) {
+		if len(lines) == 0 {
+			return "", io.EOF
+		}
+		line := lines[0]
+		lines = lines[1:]
+		return line, nil
+	}
+
+	for !ctx.ShouldInterrupt() {
+		input, err := readCompleteCommand(nextLine, s.parser)
+		if err != nil {
+			break
+		}
+
+		pipelines, err := s.parser.Parse(input, ctx.Aliases)
+		if err != nil {
+			return err
+		}
+		if err := s.ExecutePipelines(pipelines, ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,482 @@
+package services
+
+import (
+	"fmt"
+	"minishell/internal/domain"
+	"minishell/pkg/utils"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// assignVariables выполняет присваивания перед именем команды
+func (s *CommandService) assignVariables(assignments []*domain.Assignment, ctx *domain.ExecutionContext) error {
+	for _, assignment := range assignments {
+		if err := s.assign(assignment, ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandAssignments раскрывает значения присваиваний перед командой.
+// Присваивания элементам массивов для отдельной команды не поддерживаются.
+func (s *CommandService) expandAssignments(assignments []*domain.Assignment, ctx *domain.ExecutionContext) ([]*domain.Assignment, error) {
+	expanded := make([]*domain.Assignment, 0, len(assignments))
+	for _, assignment := range assignments {
+		if assignment.Compound || assignment.HasSubscript() {
+			continue
+		}
+
+		if ctx.IsReadonly(assignment.Name) {
+			return nil, fmt.Errorf("%s: readonly variable", assignment.Name)
+		}
+
+		value, err := s.expander.ExpandValue(assignment.Value, ctx)
+		if err != nil {
+			return nil, err
+		}
+		value, err = s.convertValue(assignment.Name, ctx.GetEnv(assignment.Name), value, assignment.Append, ctx)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, &domain.Assignment{Name: assignment.Name, Value: value})
+	}
+	return expanded, nil
+}
+
+// withTemporaryVariables выполняет функцию или встроенную команду с
+// экспортированными на время выполнения присваиваниями перед командой
+func (s *CommandService) withTemporaryVariables(cmd *domain.Command, ctx *domain.ExecutionContext, run func() error) error {
+	if len(cmd.Assignments) == 0 {
+		return run()
+	}
+
+	type savedVariable struct {
+		value string
+		set   bool
+		attrs domain.VariableAttribute
+	}
+	saved := make(map[string]savedVariable, len(cmd.Assignments))
+
+	for _, assignment := range cmd.Assignments {
+		if _, ok := saved[assignment.Name]; !ok {
+			value, set := ctx.LookupEnv(assignment.Name)
+			saved[assignment.Name] = savedVariable{value: value, set: set, attrs: ctx.Attributes[assignment.Name]}
+		}
+		ctx.SetEnv(assignment.Name, assignment.Value)
+		ctx.SetAttribute(assignment.Name, domain.AttrExported, true)
+	}
+
+	defer func() {
+		for name, variable := range saved {
+			if variable.set {
+				ctx.SetEnv(name, variable.value)
+			} else {
+				ctx.UnsetEnv(name)
+			}
+			ctx.SetAttribute(name, ^domain.VariableAttribute(0), false)
+			ctx.SetAttribute(name, variable.attrs, true)
+		}
+	}()
+
+	return run()
+}
+
+// assign выполняет одно присваивание переменной или элементу массива.
+// Ссылки declare -n разыменовываются, атрибуты переменной применяются к значению.
+func (s *CommandService) assign(assignment *domain.Assignment, ctx *domain.ExecutionContext) error {
+	name := ctx.ResolveName(assignment.Name)
+	if ctx.IsReadonly(name) {
+		return fmt.Errorf("%s: readonly variable", name)
+	}
+	assignment = assignment.WithName(name)
+
+	if assignment.Compound {
+		return s.assignCompound(assignment, ctx)
+	}
+
+	value, err := s.expander.ExpandValue(assignment.Value, ctx)
+	if err != nil {
+		return err
+	}
+
+	key := ""
+	if assignment.HasSubscript() {
+		if key, err = s.expander.ExpandSubscript(name, assignment.Subscript, ctx); err != nil {
+			return err
+		}
+	}
+	_, err = ctx.AssignValue(name, key, value, assignment.Append, s.arithmetic(ctx))
+	return err
+}
+
+// convertValue применяет к значению атрибуты переменной (см. ConvertValue)
+func (s *CommandService) convertValue(name, old, value string, appendValue bool, ctx *domain.ExecutionContext) (string, error) {
+	return ctx.ConvertValue(name, old, value, appendValue, s.arithmetic(ctx))
+}
+
+// arithmetic возвращает вычислитель арифметики для атрибута -i
+func (s *CommandService) arithmetic(ctx *domain.ExecutionContext) domain.ArithmeticEvaluator {
+	return func(expr string) (int, error) {
+		return s.expander.EvaluateArithmetic(expr, ctx)
+	}
+}
+
+// assignCompound выполняет составное присваивание NAME=(...) или NAME+=(...).
+// Элементы раскрываются до замены массива, поэтому a=(${a[@]} x) работает.
+func (s *CommandService) assignCompound(assignment *domain.Assignment, ctx *domain.ExecutionContext) error {
+	existing, isArray := ctx.GetArray(assignment.Name)
+	associative := isArray && existing.Associative
+
+	var arr *domain.Array
+	switch {
+	case assignment.Append && isArray:
+		arr = existing
+	case associative:
+		arr = domain.NewAssociativeArray()
+	default:
+		arr = domain.NewIndexedArray()
+		if value, ok := ctx.LookupEnv(assignment.Name); ok && assignment.Append {
+			arr.Set("0", value)
+		}
+	}
+
+	// Копия нужна, чтобы при ошибке массив остался прежним
+	updated := domain.NewIndexedArray()
+	if associative {
+		updated = domain.NewAssociativeArray()
+	}
+	for _, key := range arr.Keys() {
+		value, _ := arr.Get(key)
+		updated.Set(key, value)
+	}
+
+	next := updated.NextIndex()
+	for _, element := range assignment.Elements {
+		if !element.HasSubscript() {
+			if associative {
+				return fmt.Errorf("%s: %s: must use subscript when assigning associative array", assignment.Name, element.Value)
+			}
+			values, err := s.expander.ExpandWords([]string{element.Value}, ctx)
+			if err != nil {
+				return err
+			}
+			for _, value := range values {
+				if value, err = s.convertValue(assignment.Name, "", value, false, ctx); err != nil {
+					return err
+				}
+				updated.Set(strconv.Itoa(next), value)
+				next++
+			}
+			continue
+		}
+
+		key, err := s.expander.ExpandValue(element.Subscript, ctx)
+		if err != nil {
+			return err
+		}
+		if !associative {
+			if key, err = s.expander.ExpandSubscript(assignment.Name, element.Subscript, ctx); err != nil {
+				return err
+			}
+			index, _ := strconv.Atoi(key)
+			next = index + 1
+		} else if key == "" {
+			return fmt.Errorf("%s[]: bad array subscript", assignment.Name)
+		}
+
+		value, err := s.expander.ExpandValue(element.Value, ctx)
+		if err != nil {
+			return err
+		}
+		if value, err = s.convertValue(assignment.Name, "", value, false, ctx); err != nil {
+			return err
+		}
+		updated.Set(key, value)
+	}
+
+	ctx.SetArray(assignment.Name, updated)
+	return nil
+}
+
+// executeUnset выполняет команду unset [-v|-f|-n] name...; без флагов удаляется
+// переменная, а если ее нет - функция с тем же именем. Для ссылки declare -n
+// удаляется переменная, на которую она указывает, а с -n - сама ссылка.
+func (s *CommandService) executeUnset(cmd *domain.Command, ctx *domain.ExecutionContext) error {
+	variables, functions, nameref := false, false, false
+
+	args := cmd.Args
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") && len(args[0]) > 1 {
+		arg := args[0]
+		args = args[1:]
+		if arg == "--" {
+			break
+		}
+		for _, flag := range arg[1:] {
+			switch flag {
+			case 'v':
+				variables = true
+			case 'f':
+				functions = true
+			case 'n':
+				nameref = true
+			default:
+				ctx.UpdateExitCode(2)
+				return fmt.Errorf("unset: -%c: invalid option", flag)
+			}
+		}
+	}
+	if variables && functions {
+		ctx.UpdateExitCode(1)
+		return fmt.Errorf("unset: cannot simultaneously unset a function and a variable")
+	}
+
+	exitCode := 0
+	for _, arg := range args {
+		if functions {
+			ctx.UnsetFunction(arg)
+			continue
+		}
+
+		name, subscript, hasSubscript := splitArrayReference(arg)
+		if !isValidName(name) {
+			s.presenter.ShowError(fmt.Sprintf("unset: `%s': not a valid identifier", arg))
+			exitCode = 1
+			continue
+		}
+
+		if !nameref {
+			name = ctx.ResolveName(name)
+		}
+		if ctx.IsReadonly(name) {
+			s.presenter.ShowError(fmt.Sprintf("unset: %s: cannot unset: readonly variable", name))
+			exitCode = 1
+			continue
+		}
+
+		if !hasSubscript {
+			if !variables && !ctx.IsVariableSet(name) && ctx.Attributes[name] == 0 {
+				ctx.UnsetFunction(name)
+			}
+			ctx.UnsetVariable(name)
+			continue
+		}
+
+		key, err := s.expander.ExpandSubscript(name, subscript, ctx)
+		if err != nil {
+			s.presenter.ShowError("unset: " + err.Error())
+			exitCode = 1
+			continue
+		}
+		if arr, ok := ctx.GetArray(name); ok {
+			arr.Unset(key)
+		} else if key == "0" {
+			ctx.UnsetVariable(name)
+		}
+	}
+
+	ctx.UpdateExitCode(exitCode)
+	return nil
+}
+
+// executeExport выполняет команду export [-n] [-p] [name[=value] ...]
+func (s *CommandService) executeExport(cmd *domain.Command, ctx *domain.ExecutionContext) error {
+	unexport, print := false, false
+
+	args := cmd.Args
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") && len(args[0]) > 1 {
+		arg := args[0]
+		args = args[1:]
+		if arg == "--" {
+			break
+		}
+		for _, flag := range arg[1:] {
+			switch flag {
+			case 'n':
+				unexport = true
+			case 'p':
+				print = true
+			default:
+				ctx.UpdateExitCode(2)
+				return fmt.Errorf("export: -%c: invalid option", flag)
+			}
+		}
+	}
+
+	if len(args) == 0 || (print && !unexport) {
+		s.printExported(ctx)
+		ctx.UpdateExitCode(0)
+		return nil
+	}
+
+	exitCode := 0
+	for _, arg := range args {
+		assignment, err := s.parser.ParseAssignment(arg)
+		if err != nil {
+			s.presenter.ShowError("export: " + err.Error())
+			exitCode = 1
+			continue
+		}
+
+		name := arg
+		if assignment != nil {
+			name = assignment.Name
+		}
+		if !isValidName(name) || (assignment != nil && assignment.HasSubscript()) {
+			s.presenter.ShowError(fmt.Sprintf("export: `%s': not a valid identifier", arg))
+			exitCode = 1
+			continue
+		}
+
+		if assignment != nil {
+			if err := s.assign(assignment, ctx); err != nil {
+				s.presenter.ShowError("export: " + err.Error())
+				exitCode = 1
+				continue
+			}
+		}
+		ctx.SetAttribute(name, domain.AttrExported, !unexport)
+	}
+
+	ctx.UpdateExitCode(exitCode)
+	return nil
+}
+
+// printExported выводит экспортированные переменные в виде команд declare -x
+func (s *CommandService) printExported(ctx *domain.ExecutionContext) {
+	s.printDeclarations(&declareOptions{add: domain.AttrExported}, ctx)
+}
+
+// printVariables выводит все переменные в форме, пригодной для повторного выполнения
+func (s *CommandService) printVariables(ctx *domain.ExecutionContext) {
+	names := make([]string, 0, len(ctx.Environment)+len(ctx.Arrays))
+	for name := range ctx.Environment {
+		names = append(names, name)
+	}
+	for name := range ctx.Arrays {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !isValidName(name) {
+			continue
+		}
+		if arr, ok := ctx.GetArray(name); ok {
+			fmt.Fprintln(s.stdout, formatArray(name, arr))
+			continue
+		}
+		fmt.Fprintf(s.stdout, "%s=%s\n", name, utils.ShellQuote(ctx.GetEnv(name)))
+	}
+}
+
+// formatArray форматирует массив как составное присваивание
+func formatArray(name string, arr *domain.Array) string {
+	if arr.Associative {
+		return "declare -A " + name + "=" + formatArrayElements(arr)
+	}
+	return name + "=" + formatArrayElements(arr)
+}
+
+// formatArrayElements форматирует элементы массива в виде ([key]='value' ...)
+func formatArrayElements(arr *domain.Array) string {
+	var result strings.Builder
+	result.WriteByte('(')
+
+	for i, key := range arr.Keys() {
+		if i > 0 {
+			result.WriteByte(' ')
+		}
+		value, _ := arr.Get(key)
+		result.WriteString("[" + formatArrayKey(key, arr.Associative) + "]=" + utils.ShellQuote(value))
+	}
+
+	result.WriteByte(')')
+	return result.String()
+}
+
+// formatArrayKey экранирует ключ ассоциативного массива при необходимости
+func formatArrayKey(key string, associative bool) string {
+	if associative && strings.ContainsAny(key, " \t\n'\"\\$`[]*?") {
+		return utils.ShellQuote(key)
+	}
+	return key
+}
+
+// executeEnv выполняет команду env [-i] [-u name] [NAME=value ...] [command [args ...]]:
+// без команды выводит окружение, иначе запускает внешнюю команду с измененным окружением
+func (s *CommandService) executeEnv(cmd *domain.Command, ctx *domain.ExecutionContext) error {
+	env := make(map[string]string)
+	for _, pair := range ctx.ExportedEnvironment() {
+		if name, value, ok := strings.Cut(pair, "="); ok {
+			env[name] = value
+		}
+	}
+
+	args := cmd.Args
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		arg := args[0]
+		args = args[1:]
+
+		switch {
+		case arg == "--":
+		case arg == "-" || arg == "-i" || arg == "--ignore-environment":
+			env = make(map[string]string)
+			continue
+		case arg == "-u" || arg == "--unset":
+			if len(args) == 0 {
+				ctx.UpdateExitCode(125)
+				return fmt.Errorf("env: option requires an argument -- 'u'")
+			}
+			delete(env, args[0])
+			args = args[1:]
+			continue
+		default:
+			ctx.UpdateExitCode(125)
+			return fmt.Errorf("env: invalid option -- '%s'", strings.TrimLeft(arg, "-"))
+		}
+		break
+	}
+
+	for len(args) > 0 && strings.Contains(args[0], "=") && !strings.HasPrefix(args[0], "=") {
+		name, value, _ := strings.Cut(args[0], "=")
+		env[name] = value
+		args = args[1:]
+	}
+
+	pairs := make([]string, 0, len(env))
+	for name, value := range env {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+
+	if len(args) == 0 {
+		for _, pair := range pairs {
+			fmt.Fprintln(s.stdout, pair)
+		}
+		ctx.UpdateExitCode(0)
+		return nil
+	}
+
+	child := domain.NewCommand(args[0])
+	child.Args = args[1:]
+	child.Environment = pairs
+
+	output, exitCode, err := s.system.ExecuteCommand(child, s.takeInput())
+	ctx.UpdateExitCode(exitCode)
+	if err != nil {
+		return fmt.Errorf("env: %w", err)
+	}
+	fmt.Fprint(s.stdout, string(output))
+	return nil
+}
+
+// splitArrayReference разбирает ссылку вида name[subscript]
+func splitArrayReference(ref string) (name, subscript string, ok bool) {
+	open := strings.IndexByte(ref, '[')
+	if open <= 0 || !strings.HasSuffix(ref, "]") {
+		return ref, "", false
+	}
+	return ref[:open], ref[open+1 : len(ref)-1], true
+}
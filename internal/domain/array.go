@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"maps"
+	"sort"
+	"strconv"
+)
+
+// Array - значение переменной-массива: индексированного или ассоциативного
+type Array struct {
+	Associative bool
+	// elements хранит элементы по ключу; ключи индексированного массива -
+	// неотрицательные числа в десятичной записи
+	elements map[string]string
+}
+
+// NewIndexedArray создает индексированный массив из списка значений
+func NewIndexedArray(values ...string) *Array {
+	arr := &Array{elements: make(map[string]string)}
+	arr.Append(values...)
+	return arr
+}
+
+// NewAssociativeArray создает пустой ассоциативный массив
+func NewAssociativeArray() *Array {
+	return &Array{Associative: true, elements: make(map[string]string)}
+}
+
+// Clone возвращает независимую копию массива
+func (a *Array) Clone() *Array {
+	return &Array{Associative: a.Associative, elements: maps.Clone(a.elements)}
+}
+
+// Get возвращает элемент по ключу
+func (a *Array) Get(key string) (string, bool) {
+	value, ok := a.elements[key]
+	return value, ok
+}
+
+// Set устанавливает элемент по ключу
+func (a *Array) Set(key, value string) {
+	a.elements[key] = value
+}
+
+// Unset удаляет элемент по ключу
+func (a *Array) Unset(key string) {
+	delete(a.elements, key)
+}
+
+// Clear удаляет все элементы
+func (a *Array) Clear() {
+	a.elements = make(map[string]string)
+}
+
+// Len возвращает количество элементов
+func (a *Array) Len() int {
+	return len(a.elements)
+}
+
+// Keys возвращает ключи: индексы по возрастанию или отсортированные ключи
+// ассоциативного массива
+func (a *Array) Keys() []string {
+	keys := make([]string, 0, len(a.elements))
+	for key := range a.elements {
+		keys = append(keys, key)
+	}
+
+	if a.Associative {
+		sort.Strings(keys)
+		return keys
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		left, _ := strconv.Atoi(keys[i])
+		right, _ := strconv.Atoi(keys[j])
+		return left < right
+	})
+	return keys
+}
+
+// Values возвращает значения в порядке ключей
+func (a *Array) Values() []string {
+	keys := a.Keys()
+	values := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, a.elements[key])
+	}
+	return values
+}
+
+// NextIndex возвращает индекс, следующий за наибольшим
+func (a *Array) NextIndex() int {
+	next := 0
+	for key := range a.elements {
+		if index, err := strconv.Atoi(key); err == nil && index >= next {
+			next = index + 1
+		}
+	}
+	return next
+}
+
+// Append добавляет значения в конец индексированного массива
+func (a *Array) Append(values ...string) {
+	next := a.NextIndex()
+	for i, value := range values {
+		a.elements[strconv.Itoa(next+i)] = value
+	}
+}
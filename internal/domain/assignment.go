@@ -0,0 +1,39 @@
+package domain
+
+// Assignment - присваивание переменной: NAME=value, NAME+=value,
+// NAME[subscript]=value или составное NAME=(elements)
+type Assignment struct {
+	Name string
+	// Subscript - индекс или ключ элемента массива, пустой для всей переменной
+	Subscript string
+	Value     string
+	Append    bool
+	Compound  bool
+	Elements  []*ArrayElement
+}
+
+// ArrayElement - элемент составного присваивания: value или [subscript]=value
+type ArrayElement struct {
+	Subscript string
+	Value     string
+}
+
+// HasSubscript проверяет, присваивается ли отдельный элемент массива
+func (a *Assignment) HasSubscript() bool {
+	return a.Subscript != ""
+}
+
+// HasSubscript проверяет, задан ли ключ элемента явно
+func (e *ArrayElement) HasSubscript() bool {
+	return e.Subscript != ""
+}
+
+// WithName возвращает копию присваивания с другим именем переменной
+func (a *Assignment) WithName(name string) *Assignment {
+	if a.Name == name {
+		return a
+	}
+	renamed := *a
+	renamed.Name = name
+	return &renamed
+}
@@ -1,5 +1,42 @@
 package domain
 
+import (
+	"errors"
+	"sort"
+)
+
+// ErrCommandNotFound - команда не найдена ни среди встроенных и функций,
+// ни в каталогах PATH (код завершения 127)
+var ErrCommandNotFound = errors.New("command not found")
+
+// builtinCommands - имена встроенных команд shell
+var builtinCommands = map[string]bool{
+	"cd":       true,
+	"pwd":      true,
+	"echo":     true,
+	"kill":     true,
+	"ps":       true,
+	"exit":     true,
+	"local":    true,
+	"return":   true,
+	"alias":    true,
+	"unalias":  true,
+	"shopt":    true,
+	"set":      true,
+	"shift":    true,
+	"unset":    true,
+	"declare":  true,
+	"typeset":  true,
+	"export":   true,
+	"env":      true,
+	"readonly": true,
+	"source":   true,
+	".":        true,
+	"history":  true,
+	"complete": true,
+	"compgen":  true,
+}
+
 // Command - доменная сущность команды
 type Command struct {
 	Name       string
@@ -8,6 +45,12 @@ type Command struct {
 	Output     string
 	Append     bool
 	Background bool
+	Function   *Function
+	// Assignments - присваивания перед именем команды
+	Assignments []*Assignment
+	// Environment - окружение дочернего процесса в виде NAME=value;
+	// nil означает окружение самого shell
+	Environment []string
 }
 
 // NewCommand создает новую команду
@@ -34,15 +77,27 @@ func (c *Command) SetOutput(file string, append bool) {
 	c.Append = append
 }
 
+// IsFunctionDefinition проверяет, является ли команда определением функции
+func (c *Command) IsFunctionDefinition() bool {
+	return c.Function != nil
+}
+
+// AddAssignment добавляет присваивание перед именем команды
+func (c *Command) AddAssignment(assignment *Assignment) {
+	c.Assignments = append(c.Assignments, assignment)
+}
+
 // IsBuiltin проверяет, является ли команда встроенной
 func (c *Command) IsBuiltin() bool {
-	builtins := map[string]bool{
-		"cd":   true,
-		"pwd":  true,
-		"echo": true,
-		"kill": true,
-		"ps":   true,
-		"exit": true,
+	return builtinCommands[c.Name]
+}
+
+// BuiltinNames возвращает имена встроенных команд в алфавитном порядке
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtinCommands))
+	for name := range builtinCommands {
+		names = append(names, name)
 	}
-	return builtins[c.Name]
+	sort.Strings(names)
+	return names
 }
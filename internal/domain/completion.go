@@ -0,0 +1,61 @@
+package domain
+
+// CompletionLine - строка ввода, разобранная для дополнения слова под курсором
+type CompletionLine struct {
+	// Words - слова команды, в которой стоит курсор, в исходном виде
+	Words []string
+	// Current - номер дополняемого слова в Words
+	Current int
+	// Start - смещение начала дополняемого слова в строке (в байтах)
+	Start int
+	// Raw - дополняемое слово от начала до курсора в исходном виде
+	Raw string
+	// Prefix - дополняемое слово до курсора после снятия кавычек
+	Prefix string
+	// Quote - незакрытая кавычка в дополняемом слове или 0
+	Quote byte
+	// QuoteStart - смещение незакрытой кавычки в Raw
+	QuoteStart int
+	// CommandPosition - слово стоит на месте имени команды
+	CommandPosition bool
+	// Command - имя команды, аргумент которой дополняется
+	Command string
+}
+
+// CommandOption - параметр внешней команды, найденный в ее справке
+type CommandOption struct {
+	// Name - имя параметра (-a, --all); для параметров вида --name=VALUE
+	// включает =
+	Name        string
+	Description string
+}
+
+// CompletionSpec - спецификация дополнения аргументов команды,
+// заданная встроенной командой complete
+type CompletionSpec struct {
+	// Actions - виды предлагаемых имен (complete -A): файлы, каталоги,
+	// команды, переменные и т. д.
+	Actions []string
+	// Options - параметры complete -o
+	Options []string
+	// WordList - список слов -W; раскрывается при каждом дополнении
+	WordList string
+	// Function - функция -F, заполняющая массив COMPREPLY
+	Function string
+	// Prefix и Suffix добавляются к каждому варианту (-P и -S)
+	Prefix string
+	Suffix string
+	// Filter - шаблон -X: подходящие варианты удаляются, с ! в начале -
+	// удаляются неподходящие
+	Filter string
+}
+
+// HasOption проверяет, задан ли параметр complete -o
+func (s *CompletionSpec) HasOption(name string) bool {
+	for _, option := range s.Options {
+		if option == name {
+			return true
+		}
+	}
+	return false
+}
@@ -1,20 +1,79 @@
 package domain
 
+import (
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
 // ExecutionContext - доменная сущность контекста выполнения
 type ExecutionContext struct {
-	CurrentDir   string
-	Environment  map[string]string
-	LastExitCode int
-	IsRunning    bool
+	CurrentDir        string
+	Environment       map[string]string
+	Arrays            map[string]*Array
+	Attributes        map[string]VariableAttribute
+	Functions         map[string]*Function
+	Aliases           map[string]string
+	CompletionSpecs   map[string]*CompletionSpec
+	Options           map[string]bool
+	History           *History
+	Frames            []*CallFrame
+	LastExitCode      int
+	LastBackgroundPID int
+	ShellPID          int
+	ShellName         string
+	IsRunning         bool
+	Interactive       bool
+	IsReturning       bool
+	SourceDepth       int
+
+	// Positional - стек позиционных параметров; нижний уровень - аргументы
+	// скрипта, каждый вызов функции добавляет свой уровень
+	Positional [][]string
 }
 
 // NewExecutionContext создает новый контекст выполнения
 func NewExecutionContext() *ExecutionContext {
 	return &ExecutionContext{
-		Environment:  make(map[string]string),
-		IsRunning:    true,
-		LastExitCode: 0,
+		Environment:     make(map[string]string),
+		Functions:       make(map[string]*Function),
+		Aliases:         make(map[string]string),
+		CompletionSpecs: make(map[string]*CompletionSpec),
+		Arrays:          make(map[string]*Array),
+		Attributes:      make(map[string]VariableAttribute),
+		Options:         make(map[string]bool),
+		History:         NewHistory(),
+		Positional:      [][]string{nil},
+		IsRunning:       true,
+		LastExitCode:    0,
+		ShellName:       "minishell",
+	}
+}
+
+// Clone возвращает копию контекста для подоболочки: изменения переменных,
+// функций, псевдонимов и опций в копии не влияют на исходный контекст.
+// История остается общей.
+func (ctx *ExecutionContext) Clone() *ExecutionContext {
+	clone := *ctx
+	clone.Environment = maps.Clone(ctx.Environment)
+	clone.Attributes = maps.Clone(ctx.Attributes)
+	clone.Functions = maps.Clone(ctx.Functions)
+	clone.Aliases = maps.Clone(ctx.Aliases)
+	clone.CompletionSpecs = maps.Clone(ctx.CompletionSpecs)
+	clone.Options = maps.Clone(ctx.Options)
+	clone.Positional = slices.Clone(ctx.Positional)
+
+	clone.Arrays = make(map[string]*Array, len(ctx.Arrays))
+	for name, arr := range ctx.Arrays {
+		clone.Arrays[name] = arr.Clone()
 	}
+	clone.Frames = make([]*CallFrame, len(ctx.Frames))
+	for i, frame := range ctx.Frames {
+		clone.Frames[i] = frame.clone()
+	}
+	return &clone
 }
 
 // UpdateCurrentDir обновляет текущую директорию
@@ -32,11 +91,193 @@ func (ctx *ExecutionContext) GetEnv(key string) string {
 	return ctx.Environment[key]
 }
 
+// LookupEnv получает переменную окружения и признак ее наличия
+func (ctx *ExecutionContext) LookupEnv(key string) (string, bool) {
+	value, ok := ctx.Environment[key]
+	return value, ok
+}
+
+// UnsetEnv удаляет переменную окружения
+func (ctx *ExecutionContext) UnsetEnv(key string) {
+	delete(ctx.Environment, key)
+}
+
+// GetArray возвращает переменную-массив
+func (ctx *ExecutionContext) GetArray(name string) (*Array, bool) {
+	arr, ok := ctx.Arrays[name]
+	return arr, ok
+}
+
+// SetArray делает переменную массивом, заменяя ее прежнее значение
+func (ctx *ExecutionContext) SetArray(name string, arr *Array) {
+	delete(ctx.Environment, name)
+	ctx.Arrays[name] = arr
+}
+
+// UnsetVariable удаляет переменную вместе с ее значением-массивом и атрибутами
+func (ctx *ExecutionContext) UnsetVariable(name string) {
+	delete(ctx.Environment, name)
+	delete(ctx.Arrays, name)
+	delete(ctx.Attributes, name)
+}
+
+// IsVariableSet проверяет, установлена ли переменная или массив
+func (ctx *ExecutionContext) IsVariableSet(name string) bool {
+	if _, ok := ctx.Environment[name]; ok {
+		return true
+	}
+	_, ok := ctx.Arrays[name]
+	return ok
+}
+
+// ResolveName возвращает имя переменной, на которую ссылается name через
+// цепочку ссылок (declare -n); для обычных переменных возвращает name
+func (ctx *ExecutionContext) ResolveName(name string) string {
+	for i := 0; i < MaxNamerefDepth; i++ {
+		if !ctx.HasAttribute(name, AttrNameref) {
+			return name
+		}
+		target := ctx.Environment[name]
+		if target == "" || target == name {
+			return name
+		}
+		name = target
+	}
+	return name
+}
+
+// IsReadonly проверяет, запрещено ли изменение переменной
+func (ctx *ExecutionContext) IsReadonly(name string) bool {
+	return ctx.HasAttribute(name, AttrReadonly)
+}
+
+// HasAttribute проверяет, есть ли у переменной атрибут
+func (ctx *ExecutionContext) HasAttribute(name string, attr VariableAttribute) bool {
+	return ctx.Attributes[name].Has(attr)
+}
+
+// SetAttribute добавляет или снимает атрибут переменной
+func (ctx *ExecutionContext) SetAttribute(name string, attr VariableAttribute, enabled bool) {
+	attrs := ctx.Attributes[name]
+	if enabled {
+		attrs |= attr
+	} else {
+		attrs &^= attr
+	}
+
+	if attrs == 0 {
+		delete(ctx.Attributes, name)
+		return
+	}
+	ctx.Attributes[name] = attrs
+}
+
+// ArithmeticEvaluator вычисляет арифметическое выражение для переменных
+// с атрибутом -i
+type ArithmeticEvaluator func(expr string) (int, error)
+
+// AssignValue присваивает раскрытое значение переменной или, если key не
+// пуст, элементу массива. Присваивание массиву без ключа меняет элемент 0.
+// Возвращает значение после применения атрибутов.
+func (ctx *ExecutionContext) AssignValue(name, key, value string, appendValue bool, evaluate ArithmeticEvaluator) (string, error) {
+	arr, isArray := ctx.GetArray(name)
+	if !isArray && key == "" {
+		value, err := ctx.ConvertValue(name, ctx.GetEnv(name), value, appendValue, evaluate)
+		if err != nil {
+			return "", err
+		}
+		ctx.SetEnv(name, value)
+		return value, nil
+	}
+
+	if key == "" {
+		key = "0"
+	}
+	if !isArray {
+		arr = ctx.ConvertToArray(name)
+	}
+	old, _ := arr.Get(key)
+	value, err := ctx.ConvertValue(name, old, value, appendValue, evaluate)
+	if err != nil {
+		return "", err
+	}
+	arr.Set(key, value)
+	return value, nil
+}
+
+// ConvertValue применяет к новому значению атрибуты переменной: -i вычисляет
+// арифметическое выражение, -l и -u меняют регистр. При += значение
+// дописывается к старому, а для -i складывается с ним.
+func (ctx *ExecutionContext) ConvertValue(name, old, value string, appendValue bool, evaluate ArithmeticEvaluator) (string, error) {
+	if ctx.HasAttribute(name, AttrInteger) {
+		n, err := evaluate(value)
+		if err != nil {
+			return "", err
+		}
+		if appendValue {
+			base, err := evaluate(old)
+			if err != nil {
+				return "", err
+			}
+			n += base
+		}
+		value = strconv.Itoa(n)
+	} else if appendValue {
+		value = old + value
+	}
+
+	switch {
+	case ctx.HasAttribute(name, AttrLowercase):
+		value = strings.ToLower(value)
+	case ctx.HasAttribute(name, AttrUppercase):
+		value = strings.ToUpper(value)
+	}
+	return value, nil
+}
+
+// ConvertToArray превращает переменную в индексированный массив;
+// скалярное значение становится элементом 0
+func (ctx *ExecutionContext) ConvertToArray(name string) *Array {
+	arr := NewIndexedArray()
+	if value, ok := ctx.LookupEnv(name); ok {
+		arr.Set("0", value)
+	}
+	ctx.SetArray(name, arr)
+	return arr
+}
+
+// ExportedEnvironment возвращает окружение для дочерних процессов в виде
+// отсортированного списка NAME=value
+func (ctx *ExecutionContext) ExportedEnvironment() []string {
+	env := make([]string, 0, len(ctx.Attributes))
+	for name, value := range ctx.Environment {
+		if ctx.HasAttribute(name, AttrExported) {
+			env = append(env, name+"="+value)
+		}
+	}
+	sort.Strings(env)
+	return env
+}
+
+// UnsetFunction удаляет функцию; возвращает false, если функции не было
+func (ctx *ExecutionContext) UnsetFunction(name string) bool {
+	if _, ok := ctx.Functions[name]; !ok {
+		return false
+	}
+	delete(ctx.Functions, name)
+	return true
+}
+
 // UpdateExitCode обновляет код завершения последней команды
 func (ctx *ExecutionContext) UpdateExitCode(code int) {
 	ctx.LastExitCode = code
 }
 
+// UpdateLastBackgroundPID запоминает PID последнего фонового процесса
+func (ctx *ExecutionContext) UpdateLastBackgroundPID(pid int) {
+	ctx.LastBackgroundPID = pid
+}
+
 // Stop останавливает выполнение shell
 func (ctx *ExecutionContext) Stop() {
 	ctx.IsRunning = false
@@ -46,3 +287,190 @@ func (ctx *ExecutionContext) Stop() {
 func (ctx *ExecutionContext) GetPrompt() string {
 	return "minishell:" + ctx.CurrentDir + "$ "
 }
+
+// DefineFunction регистрирует функцию
+func (ctx *ExecutionContext) DefineFunction(fn *Function) {
+	ctx.Functions[fn.Name] = fn
+}
+
+// GetFunction возвращает функцию по имени
+func (ctx *ExecutionContext) GetFunction(name string) (*Function, bool) {
+	fn, ok := ctx.Functions[name]
+	return fn, ok
+}
+
+// SetOption включает или выключает опцию shell
+func (ctx *ExecutionContext) SetOption(name string, enabled bool) {
+	ctx.Options[name] = enabled
+}
+
+// IsOptionSet проверяет, включена ли опция shell
+func (ctx *ExecutionContext) IsOptionSet(name string) bool {
+	return ctx.Options[name]
+}
+
+// SetAlias устанавливает псевдоним
+func (ctx *ExecutionContext) SetAlias(name, value string) {
+	ctx.Aliases[name] = value
+}
+
+// GetAlias возвращает значение псевдонима
+func (ctx *ExecutionContext) GetAlias(name string) (string, bool) {
+	value, ok := ctx.Aliases[name]
+	return value, ok
+}
+
+// UnsetAlias удаляет псевдоним
+func (ctx *ExecutionContext) UnsetAlias(name string) bool {
+	if _, ok := ctx.Aliases[name]; !ok {
+		return false
+	}
+	delete(ctx.Aliases, name)
+	return true
+}
+
+// ClearAliases удаляет все псевдонимы
+func (ctx *ExecutionContext) ClearAliases() {
+	ctx.Aliases = make(map[string]string)
+}
+
+// SetCompletionSpec задает спецификацию дополнения аргументов команды
+func (ctx *ExecutionContext) SetCompletionSpec(name string, spec *CompletionSpec) {
+	ctx.CompletionSpecs[name] = spec
+}
+
+// GetCompletionSpec возвращает спецификацию дополнения аргументов команды
+func (ctx *ExecutionContext) GetCompletionSpec(name string) (*CompletionSpec, bool) {
+	spec, ok := ctx.CompletionSpecs[name]
+	return spec, ok
+}
+
+// UnsetCompletionSpec удаляет спецификацию дополнения команды
+func (ctx *ExecutionContext) UnsetCompletionSpec(name string) bool {
+	if _, ok := ctx.CompletionSpecs[name]; !ok {
+		return false
+	}
+	delete(ctx.CompletionSpecs, name)
+	return true
+}
+
+// ClearCompletionSpecs удаляет все спецификации дополнения
+func (ctx *ExecutionContext) ClearCompletionSpecs() {
+	ctx.CompletionSpecs = make(map[string]*CompletionSpec)
+}
+
+// PushFrame открывает новый кадр вызова функции
+func (ctx *ExecutionContext) PushFrame(frame *CallFrame) {
+	ctx.Frames = append(ctx.Frames, frame)
+	ctx.PushPositional(frame.Args)
+}
+
+// PopFrame закрывает текущий кадр и восстанавливает перекрытые local переменные
+func (ctx *ExecutionContext) PopFrame() {
+	if len(ctx.Frames) == 0 {
+		return
+	}
+
+	frame := ctx.Frames[len(ctx.Frames)-1]
+	ctx.Frames = ctx.Frames[:len(ctx.Frames)-1]
+	ctx.PopPositional()
+
+	for key, saved := range frame.saved {
+		ctx.UnsetVariable(key)
+		if saved.value != nil {
+			ctx.Environment[key] = *saved.value
+		}
+		if saved.array != nil {
+			ctx.Arrays[key] = saved.array
+		}
+		if saved.attrs != 0 {
+			ctx.Attributes[key] = saved.attrs
+		}
+	}
+}
+
+// CurrentFrame возвращает текущий кадр вызова или nil вне функции
+func (ctx *ExecutionContext) CurrentFrame() *CallFrame {
+	if len(ctx.Frames) == 0 {
+		return nil
+	}
+	return ctx.Frames[len(ctx.Frames)-1]
+}
+
+// CallDepth возвращает глубину вложенности вызовов функций
+func (ctx *ExecutionContext) CallDepth() int {
+	return len(ctx.Frames)
+}
+
+// DeclareLocal делает переменную локальной для текущего вызова функции
+func (ctx *ExecutionContext) DeclareLocal(key string) bool {
+	frame := ctx.CurrentFrame()
+	if frame == nil {
+		return false
+	}
+
+	if _, saved := frame.saved[key]; !saved {
+		variable := &savedVariable{array: ctx.Arrays[key], attrs: ctx.Attributes[key]}
+		if value, ok := ctx.Environment[key]; ok {
+			variable.value = &value
+		}
+		frame.saved[key] = variable
+
+		// Локальная переменная наследует только признак экспорта
+		ctx.UnsetVariable(key)
+		if variable.attrs.Has(AttrExported) {
+			ctx.Attributes[key] = AttrExported
+		}
+	}
+	return true
+}
+
+// PositionalArgs возвращает текущие позиционные параметры
+func (ctx *ExecutionContext) PositionalArgs() []string {
+	if len(ctx.Positional) == 0 {
+		return nil
+	}
+	return ctx.Positional[len(ctx.Positional)-1]
+}
+
+// PushPositional открывает новый уровень позиционных параметров
+func (ctx *ExecutionContext) PushPositional(args []string) {
+	ctx.Positional = append(ctx.Positional, args)
+}
+
+// PopPositional возвращает позиционные параметры предыдущего уровня
+func (ctx *ExecutionContext) PopPositional() {
+	if len(ctx.Positional) > 1 {
+		ctx.Positional = ctx.Positional[:len(ctx.Positional)-1]
+	}
+}
+
+// SetPositionalArgs заменяет позиционные параметры текущего уровня
+func (ctx *ExecutionContext) SetPositionalArgs(args []string) {
+	if len(ctx.Positional) == 0 {
+		ctx.Positional = [][]string{nil}
+	}
+	ctx.Positional[len(ctx.Positional)-1] = args
+}
+
+// ShiftPositional сдвигает позиционные параметры на n позиций влево;
+// возвращает false, если параметров меньше n
+func (ctx *ExecutionContext) ShiftPositional(n int) bool {
+	args := ctx.PositionalArgs()
+	if n > len(args) {
+		return false
+	}
+	ctx.SetPositionalArgs(args[n:])
+	return true
+}
+
+// RequestReturn прерывает выполнение текущей функции с заданным кодом
+func (ctx *ExecutionContext) RequestReturn(code int) {
+	ctx.LastExitCode = code
+	ctx.IsReturning = true
+}
+
+// ShouldInterrupt проверяет, нужно ли прервать выполнение списка команд
+func (ctx *ExecutionContext) ShouldInterrupt() bool {
+	return !ctx.IsRunning || ctx.IsReturning
+}
@@ -0,0 +1,17 @@
+package domain
+
+// ExpansionError - ошибка раскрытия параметра (unbound variable при set -u,
+// ${name:?message}). Она прерывает всю строку команд, а неинтерактивный
+// shell завершает, как в POSIX shell
+type ExpansionError struct {
+	Message string
+}
+
+// NewExpansionError создает ошибку раскрытия параметра
+func NewExpansionError(message string) *ExpansionError {
+	return &ExpansionError{Message: message}
+}
+
+func (e *ExpansionError) Error() string {
+	return e.Message
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// FileInfo - сведения о файле каталога
+type FileInfo struct {
+	Name         string
+	IsDir        bool
+	IsExecutable bool
+	Size         int64
+	ModTime      time.Time
+}
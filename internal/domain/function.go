@@ -0,0 +1,53 @@
+package domain
+
+// Function - доменная сущность функции shell
+type Function struct {
+	Name string
+	Body []*Pipeline
+}
+
+// NewFunction создает новую функцию
+func NewFunction(name string, body []*Pipeline) *Function {
+	return &Function{
+		Name: name,
+		Body: body,
+	}
+}
+
+// CallFrame - кадр вызова функции
+type CallFrame struct {
+	Function *Function
+	Args     []string
+	// saved хранит переменные, перекрытые через local
+	saved map[string]*savedVariable
+}
+
+// savedVariable - значение, атрибуты и массив переменной до вызова local;
+// value == nil и array == nil означают, что переменная не была установлена
+type savedVariable struct {
+	value *string
+	array *Array
+	attrs VariableAttribute
+}
+
+// NewCallFrame создает новый кадр вызова
+func NewCallFrame(fn *Function, args []string) *CallFrame {
+	return &CallFrame{
+		Function: fn,
+		Args:     args,
+		saved:    make(map[string]*savedVariable),
+	}
+}
+
+// clone копирует кадр вместе с сохраненными local переменными
+func (f *CallFrame) clone() *CallFrame {
+	frame := &CallFrame{Function: f.Function, Args: f.Args, saved: make(map[string]*savedVariable, len(f.saved))}
+	for key, saved := range f.saved {
+		variable := *saved
+		if variable.array != nil {
+			variable.array = variable.array.Clone()
+		}
+		frame.saved[key] = &variable
+	}
+	return frame
+}
@@ -0,0 +1,25 @@
+package domain
+
+// HighlightKind - вид фрагмента строки при подсветке синтаксиса
+type HighlightKind string
+
+const (
+	HighlightCommand     HighlightKind = "command"
+	HighlightError       HighlightKind = "error"
+	HighlightKeyword     HighlightKind = "keyword"
+	HighlightString      HighlightKind = "string"
+	HighlightVariable    HighlightKind = "variable"
+	HighlightOperator    HighlightKind = "operator"
+	HighlightRedirection HighlightKind = "redirection"
+	HighlightComment     HighlightKind = "comment"
+)
+
+// HighlightSpan - фрагмент строки [Start, End) (смещения в байтах);
+// фрагменты, идущие позже, перекрывают предыдущие
+type HighlightSpan struct {
+	Start int
+	End   int
+	Kind  HighlightKind
+	// Text - имя команды после снятия кавычек для фрагмента HighlightCommand
+	Text string
+}
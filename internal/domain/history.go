@@ -0,0 +1,108 @@
+package domain
+
+import "strings"
+
+// History - доменная сущность истории команд. Номера записей, как в bash,
+// не меняются при вытеснении старых записей: первая запись в списке имеет
+// номер Base.
+type History struct {
+	entries []string
+	// directories - текущие каталоги, в которых выполнялись команды;
+	// для прочитанных из файла записей каталог неизвестен
+	directories []string
+	// times - время выполнения команд (Unix-время), 0 - неизвестно
+	times []int64
+	base  int
+}
+
+// NewHistory создает пустую историю
+func NewHistory() *History {
+	return &History{base: 1}
+}
+
+// Add добавляет команду в конец истории
+func (h *History) Add(line string) {
+	h.AddAt(line, "", 0)
+}
+
+// AddAt добавляет в конец истории команду, выполненную в каталоге dir
+// в момент time (Unix-время); пустой dir и нулевой time означают, что
+// они неизвестны
+func (h *History) AddAt(line, dir string, time int64) {
+	h.entries = append(h.entries, line)
+	h.directories = append(h.directories, dir)
+	h.times = append(h.times, time)
+}
+
+// Entries возвращает записи истории от старых к новым
+func (h *History) Entries() []string {
+	return h.entries
+}
+
+// Times возвращает время выполнения записей от старых к новым
+func (h *History) Times() []int64 {
+	return h.times
+}
+
+// Len возвращает число записей
+func (h *History) Len() int {
+	return len(h.entries)
+}
+
+// Base возвращает номер первой записи
+func (h *History) Base() int {
+	return h.base
+}
+
+// Delete удаляет запись с номером number; номера следующих записей
+// сдвигаются. Возвращает false, если такой записи нет.
+func (h *History) Delete(number int) bool {
+	index := number - h.base
+	if index < 0 || index >= len(h.entries) {
+		return false
+	}
+	h.entries = append(h.entries[:index], h.entries[index+1:]...)
+	h.directories = append(h.directories[:index], h.directories[index+1:]...)
+	h.times = append(h.times[:index], h.times[index+1:]...)
+	return true
+}
+
+// Clear удаляет все записи; нумерация начинается заново
+func (h *History) Clear() {
+	h.entries = nil
+	h.directories = nil
+	h.times = nil
+	h.base = 1
+}
+
+// Truncate оставляет не более size последних записей; отрицательный size
+// снимает ограничение
+func (h *History) Truncate(size int) {
+	if size < 0 || len(h.entries) <= size {
+		return
+	}
+	removed := len(h.entries) - size
+	h.entries = append([]string(nil), h.entries[removed:]...)
+	h.directories = append([]string(nil), h.directories[removed:]...)
+	h.times = append([]int64(nil), h.times[removed:]...)
+	h.base += removed
+}
+
+// Suggest возвращает самую новую запись, которая начинается с prefix и
+// длиннее него; записи, выполненные в каталоге dir, предпочтительнее
+func (h *History) Suggest(prefix, dir string) (string, bool) {
+	suggestion, found := "", false
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		entry := h.entries[i]
+		if len(entry) <= len(prefix) || !strings.HasPrefix(entry, prefix) {
+			continue
+		}
+		if dir != "" && h.directories[i] == dir {
+			return entry, true
+		}
+		if !found {
+			suggestion, found = entry, true
+		}
+	}
+	return suggestion, found
+}
@@ -0,0 +1,27 @@
+package domain
+
+// VariableAttribute - атрибут переменной shell; атрибуты объединяются побитово
+type VariableAttribute int
+
+const (
+	// AttrExported - переменная передается в окружение дочерних процессов
+	AttrExported VariableAttribute = 1 << iota
+	// AttrReadonly - переменную нельзя изменить или удалить
+	AttrReadonly
+	// AttrInteger - значение вычисляется как арифметическое выражение
+	AttrInteger
+	// AttrLowercase - значение приводится к нижнему регистру
+	AttrLowercase
+	// AttrUppercase - значение приводится к верхнему регистру
+	AttrUppercase
+	// AttrNameref - переменная является ссылкой на другую переменную
+	AttrNameref
+)
+
+// MaxNamerefDepth - максимальная длина цепочки ссылок declare -n
+const MaxNamerefDepth = 10
+
+// Has проверяет наличие атрибута
+func (a VariableAttribute) Has(attr VariableAttribute) bool {
+	return a&attr != 0
+}
@@ -0,0 +1,72 @@
+package input_adapters
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CommandLine - аргументы запуска minishell:
+//
+//	minishell [-i] [-s] [args ...]         - команды со стандартного ввода
+//	minishell -c command [name [args ...]] - выполнить строку команд
+//	minishell script [args ...]            - выполнить файл скрипта
+type CommandLine struct {
+	// Command - строка команд для -c
+	Command    string
+	HasCommand bool
+	// ScriptPath - путь к файлу скрипта
+	ScriptPath string
+	// Name - значение $0; пустое означает имя shell по умолчанию
+	Name string
+	// Args - позиционные параметры $1, $2, ...
+	Args []string
+	// Interactive - флаг -i: интерактивный режим, даже если ввод не терминал
+	Interactive bool
+}
+
+// ParseCommandLine разбирает аргументы командной строки без имени программы
+func ParseCommandLine(args []string) (*CommandLine, error) {
+	cmdLine := &CommandLine{}
+	readStdin := false
+
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") && len(args[0]) > 1 {
+		arg := args[0]
+		args = args[1:]
+		if arg == "--" {
+			break
+		}
+		for _, flag := range arg[1:] {
+			switch flag {
+			case 'c':
+				cmdLine.HasCommand = true
+			case 's':
+				readStdin = true
+			case 'i':
+				cmdLine.Interactive = true
+			default:
+				return nil, fmt.Errorf("-%c: invalid option", flag)
+			}
+		}
+	}
+
+	switch {
+	case cmdLine.HasCommand:
+		if len(args) == 0 {
+			return nil, fmt.Errorf("-c: option requires an argument")
+		}
+		cmdLine.Command = args[0]
+		args = args[1:]
+		// Первый аргумент после строки команд становится $0
+		if len(args) > 0 {
+			cmdLine.Name = args[0]
+			args = args[1:]
+		}
+	case !readStdin && len(args) > 0:
+		cmdLine.ScriptPath = args[0]
+		cmdLine.Name = args[0]
+		args = args[1:]
+	}
+
+	cmdLine.Args = args
+	return cmdLine, nil
+}
@@ -2,17 +2,67 @@ package input_adapters
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"minishell/internal/application/ports"
 	"minishell/internal/domain"
+	line_editor "minishell/internal/infrastructure/adapters/line_editor"
+	"minishell/pkg/constants"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 )
 
+// continuationPrompt - приглашение для строк продолжения
+const continuationPrompt = "> "
+
+// Коды завершения shell
+const (
+	syntaxErrorExitCode   = 2
+	cannotExecuteExitCode = 126
+	notFoundExitCode      = 127
+	interruptedExitCode   = 130
+)
+
+// lineReader - источник строк интерактивного ввода
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+// plainLineReader читает строки без редактирования
+type plainLineReader struct {
+	reader *bufio.Reader
+}
+
+// ReadLine выводит приглашение и читает строку; в конце ввода возвращает io.EOF
+func (r *plainLineReader) ReadLine(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	return readInputLine(r.reader)
+}
+
+// readInputLine читает строку любой длины без перевода строки и \r перед
+// ним; последняя строка без перевода строки тоже возвращается, после нее -
+// io.EOF
+func readInputLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r"), nil
+}
+
 // ShellController - входной адаптер для CLI
 type ShellController struct {
 	shellService ports.ShellInputPort
+	completion   ports.CompletionInputPort
+	highlight    ports.HighlightInputPort
 	system       ports.SystemRepositoryOutputPort
 	context      *domain.ExecutionContext
 }
@@ -20,53 +70,247 @@ type ShellController struct {
 // NewShellController создает новый контроллер
 func NewShellController(
 	shellService ports.ShellInputPort,
+	completion ports.CompletionInputPort,
+	highlight ports.HighlightInputPort,
 	system ports.SystemRepositoryOutputPort,
 ) *ShellController {
 
 	ctx := domain.NewExecutionContext()
-
-	if dir, err := system.GetCurrentDirectory(); err == nil {
-		ctx.UpdateCurrentDir(dir)
-	}
+	ctx.ShellPID = os.Getpid()
 
 	env := system.GetEnvironment()
 	for k, v := range env {
 		ctx.SetEnv(k, v)
+		ctx.SetAttribute(k, domain.AttrExported, true)
+	}
+
+	if dir, err := system.GetCurrentDirectory(); err == nil {
+		ctx.UpdateCurrentDir(dir)
+		ctx.SetEnv("PWD", dir)
 	}
 
 	return &ShellController{
 		shellService: shellService,
+		completion:   completion,
+		highlight:    highlight,
 		system:       system,
 		context:      ctx,
 	}
 }
 
-// Run запускает основной цикл shell
-func (c *ShellController) Run() {
+// Run запускает shell в режиме, заданном аргументами командной строки,
+// и возвращает код завершения
+func (c *ShellController) Run(cmdLine *CommandLine) int {
+	if cmdLine.Name != "" {
+		c.context.ShellName = cmdLine.Name
+	}
+	c.context.SetPositionalArgs(cmdLine.Args)
+
+	// Скрипты и -c интерактивны только с -i; стандартный ввод - также,
+	// если и ввод, и вывод ошибок связаны с терминалом
+	c.context.Interactive = cmdLine.Interactive
+
+	switch {
+	case cmdLine.HasCommand:
+		return c.runCommand(cmdLine.Command)
+	case cmdLine.ScriptPath != "":
+		return c.runScript(cmdLine.ScriptPath)
+	}
+
+	if line_editor.IsTerminal(os.Stdin) && line_editor.IsTerminal(os.Stderr) {
+		c.context.Interactive = true
+	}
+	if !c.context.Interactive {
+		return c.runStream(os.Stdin)
+	}
+
+	c.context.SetOption(constants.OptEmacs, true)
+	c.context.SetOption(constants.OptHistexpand, true)
+	c.runInteractive()
+	return c.context.LastExitCode
+}
+
+// runInteractive запускает основной цикл shell; приглашения выводятся
+// в поток ошибок, чтобы не смешиваться с выводом команд
+func (c *ShellController) runInteractive() {
 	c.setupSignalHandling()
 
-	scanner := bufio.NewScanner(os.Stdin)
+	c.shellService.LoadHistory(c.context)
+	defer c.shellService.SaveHistory(c.context)
 
-	for c.shellService.ShouldContinue(c.context) {
-		fmt.Print(c.shellService.GetPrompt(c.context))
+	reader := c.newLineReader()
 
-		if !scanner.Scan() {
+	for c.shellService.ShouldContinue(c.context) {
+		input, err := c.readCommand(reader)
+		if errors.Is(err, line_editor.ErrInterrupted) {
+			c.context.UpdateExitCode(interruptedExitCode)
+			continue
+		}
+		if err != nil {
+			if err != io.EOF {
+				fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+			}
 			break
 		}
-
-		input := scanner.Text()
 		if input == "" {
 			continue
 		}
 
 		if err := c.shellService.ExecuteCommand(input, c.context); err != nil {
-			fmt.Println("Error:", err.Error())
+			fmt.Fprintln(os.Stderr, "Error:", err.Error())
+		}
+	}
+}
+
+// newLineReader возвращает редактор строки для терминала; если ввод не
+// терминал (shell запущен с -i), строки читаются без редактирования
+func (c *ShellController) newLineReader() lineReader {
+	if line_editor.IsTerminal(os.Stdin) {
+		editor := line_editor.NewLineEditor(os.Stdin, os.Stderr)
+		editor.SetCompleter(c.complete)
+		editor.SetHighlighter(c.highlightLine)
+		editor.SetSuggester(c.suggest)
+		return editor
+	}
+	return &plainLineReader{reader: bufio.NewReader(os.Stdin)}
+}
+
+// readCommand читает команду; незавершенный ввод (открытая кавычка, тело
+// функции) продолжается на следующих строках
+func (c *ShellController) readCommand(reader lineReader) (string, error) {
+	if editor, ok := reader.(*line_editor.LineEditor); ok {
+		editor.SetEditingMode(c.editingMode())
+		editor.SetHistory(c.context.History.Entries())
+	}
+
+	input, err := reader.ReadLine(c.shellService.GetPrompt(c.context))
+	if err != nil || input == "" {
+		return input, err
+	}
+
+	for !c.shellService.IsInputComplete(input) {
+		line, err := reader.ReadLine(continuationPrompt)
+		if errors.Is(err, line_editor.ErrInterrupted) {
+			return "", err
+		}
+		if err != nil {
+			break
+		}
+		input += "\n" + line
+	}
+	return input, nil
+}
+
+// complete возвращает варианты дополнения строки для редактора
+func (c *ShellController) complete(line string, cursor int) line_editor.Completion {
+	result := c.completion.Complete(line, cursor, c.context)
+
+	completion := line_editor.Completion{Start: result.Start}
+	for _, candidate := range result.Candidates {
+		completion.Candidates = append(completion.Candidates, line_editor.Candidate{
+			Value:       candidate.Value,
+			Display:     candidate.Display,
+			NoSpace:     candidate.NoSpace,
+			Description: candidate.Description,
+		})
+	}
+	return completion
+}
+
+// highlightLine возвращает фрагменты подсветки синтаксиса строки для редактора
+func (c *ShellController) highlightLine(line string) []line_editor.Highlight {
+	var highlights []line_editor.Highlight
+	for _, span := range c.highlight.Highlight(line, c.context) {
+		highlights = append(highlights, line_editor.Highlight{
+			Start: span.Start,
+			End:   span.End,
+			Style: span.Style,
+		})
+	}
+	return highlights
+}
+
+// suggest возвращает подсказку из истории для введенной строки
+func (c *ShellController) suggest(line string) string {
+	return c.shellService.SuggestHistory(line, c.context)
+}
+
+// editingMode возвращает режим редактирования строки, выбранный set -o vi или set -o emacs
+func (c *ShellController) editingMode() line_editor.EditingMode {
+	if c.context.IsOptionSet(constants.OptVi) {
+		return line_editor.ViMode
+	}
+	return line_editor.EmacsMode
+}
+
+// runCommand выполняет строку команд, переданную через -c
+func (c *ShellController) runCommand(command string) int {
+	if err := c.shellService.ExecuteCommand(command, c.context); err != nil {
+		return c.commandErrorExitCode(err)
+	}
+	return c.context.LastExitCode
+}
+
+// commandErrorExitCode возвращает код, с которым неинтерактивный shell
+// завершается после ошибки команды: 2 для синтаксической ошибки, а для
+// ошибки раскрытия параметра - код, установленный при ее выполнении
+func (c *ShellController) commandErrorExitCode(err error) int {
+	var expansionErr *domain.ExpansionError
+	if errors.As(err, &expansionErr) {
+		return c.context.LastExitCode
+	}
+	return syntaxErrorExitCode
+}
+
+// runScript выполняет файл скрипта по одной законченной команде, чтобы
+// псевдонимы и функции, определенные в скрипте, действовали на следующие строки.
+// Строка #! в начале файла пропускается как комментарий.
+func (c *ShellController) runScript(path string) int {
+	data, err := c.system.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "minishell: %s: No such file or directory\n", path)
+			return notFoundExitCode
+		}
+		fmt.Fprintf(os.Stderr, "minishell: %s: %v\n", path, errors.Unwrap(err))
+		return cannotExecuteExitCode
+	}
+
+	return c.runStream(strings.NewReader(string(data)))
+}
+
+// runStream выполняет команды неинтерактивного ввода (скрипта или stdin)
+// по одной законченной команде
+func (c *ShellController) runStream(input io.Reader) int {
+	reader := bufio.NewReader(input)
+	var readErr error
+
+	nextLine := func() (string, error) {
+		line, err := readInputLine(reader)
+		if err != nil {
+			readErr = err
+		}
+		return line, err
+	}
+
+	for c.shellService.ShouldContinue(c.context) {
+		command, err := c.shellService.ReadCommand(nextLine)
+		if err != nil {
+			break
+		}
+
+		// Синтаксическая ошибка и ошибка раскрытия параметра прерывают
+		// неинтерактивный shell
+		if err := c.shellService.ExecuteCommand(command, c.context); err != nil {
+			return c.commandErrorExitCode(err)
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+	if readErr != nil && readErr != io.EOF {
+		fmt.Fprintf(os.Stderr, "Error reading input: %v\n", readErr)
+		return 1
 	}
+	return c.context.LastExitCode
 }
 
 // setupSignalHandling настраивает обработку сигналов
@@ -79,8 +323,7 @@ func (c *ShellController) setupSignalHandling() {
 			sig := <-sigChan
 			switch sig {
 			case syscall.SIGINT:
-				fmt.Println("\nInterrupted")
-				fmt.Print(c.shellService.GetPrompt(c.context))
+				fmt.Fprintln(os.Stderr, "\nInterrupted")
 			case syscall.SIGTERM:
 				c.shellService.ExecuteCommand("exit", c.context)
 			}
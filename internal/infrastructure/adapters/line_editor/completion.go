@@ -0,0 +1,201 @@
+package line_editor
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// completionQueryItems - число вариантов, начиная с которого перед выводом
+// списка задается вопрос
+const completionQueryItems = 100
+
+// Candidate - вариант дополнения
+type Candidate struct {
+	// Value - текст, заменяющий строку от начала дополнения до курсора
+	Value string
+	// Display - имя варианта в списке
+	Display string
+	// NoSpace - не добавлять пробел после единственного варианта
+	NoSpace bool
+	// Description - описание, выводимое в списке рядом с именем
+	Description string
+}
+
+// Completion - варианты дополнения текста строки от Start до курсора
+type Completion struct {
+	// Start - смещение начала заменяемого текста в строке (в байтах)
+	Start      int
+	Candidates []Candidate
+}
+
+// Completer возвращает варианты дополнения строки line с курсором
+// в позиции cursor (смещение в байтах)
+type Completer func(line string, cursor int) Completion
+
+// SetCompleter задает функцию дополнения по Tab
+func (e *LineEditor) SetCompleter(completer Completer) {
+	e.completer = completer
+}
+
+// complete дополняет слово перед курсором (Tab): единственный вариант
+// вставляется целиком, из нескольких - их общее начало. Следующий Tab
+// выводит список вариантов.
+func complete(e *LineEditor, _ key) {
+	if e.completer == nil {
+		selfInsert(e, runeKey('\t'))
+		return
+	}
+
+	line := string(e.buffer)
+	cursor := len(string(e.buffer[:e.cursor]))
+	completion := e.completer(line, cursor)
+	start := utf8.RuneCountInString(line[:completion.Start])
+	candidates := completion.Candidates
+
+	switch {
+	case len(candidates) == 0:
+		e.bell()
+	case len(candidates) == 1:
+		text := candidates[0].Value
+		if !candidates[0].NoSpace {
+			text += " "
+		}
+		e.replaceCompletion(start, text)
+	case e.lastAction == actionComplete:
+		e.listCandidates(candidates)
+	default:
+		// Вставляется общее начало вариантов; следующий Tab выведет список
+		prefix := commonPrefix(candidates)
+		if utf8.RuneCountInString(prefix) <= e.cursor-start || !e.replaceCompletion(start, prefix) {
+			e.bell()
+		}
+		e.action = actionComplete
+	}
+}
+
+// replaceCompletion заменяет текст от start до курсора на text; возвращает
+// false, если строка не изменилась
+func (e *LineEditor) replaceCompletion(start int, text string) bool {
+	if string(e.buffer[start:e.cursor]) == text {
+		return false
+	}
+	e.saveUndo()
+	e.remove(start, e.cursor)
+	e.insert([]rune(text))
+	e.refresh()
+	return true
+}
+
+// listCandidates выводит варианты под строкой в колонках и перерисовывает
+// строку; при большом числе вариантов сначала спрашивает подтверждение
+func (e *LineEditor) listCandidates(candidates []Candidate) {
+	cursor := e.cursor
+	e.cursor = len(e.buffer)
+	e.refresh()
+	e.cursor = cursor
+
+	if len(candidates) >= completionQueryItems {
+		fmt.Fprintf(e.out, "\r\nDisplay all %d possibilities? (y or n)", len(candidates))
+		k, err := e.readNextKey()
+		if err != nil || (k != runeKey('y') && k != runeKey('Y') && k != runeKey(' ')) {
+			io.WriteString(e.out, "\r\n")
+			e.cursorRow = 0
+			e.refresh()
+			return
+		}
+	}
+
+	list := e.formatColumns(candidates)
+	if hasDescriptions(candidates) {
+		list = e.formatDescriptions(candidates)
+	}
+	io.WriteString(e.out, "\r\n"+list)
+	e.cursorRow = 0
+	e.refresh()
+}
+
+// formatColumns располагает имена вариантов в колонках по ширине
+// терминала; варианты идут сверху вниз, затем слева направо
+func (e *LineEditor) formatColumns(candidates []Candidate) string {
+	width := 0
+	for _, candidate := range candidates {
+		width = max(width, displayWidth(candidate.Display))
+	}
+	width += 2
+
+	columns := max(e.columns/width, 1)
+	rows := (len(candidates) + columns - 1) / columns
+
+	var out strings.Builder
+	for row := 0; row < rows; row++ {
+		for column := 0; column < columns; column++ {
+			i := column*rows + row
+			if i >= len(candidates) {
+				break
+			}
+			display := candidates[i].Display
+			out.WriteString(display)
+			if column < columns-1 && i+rows < len(candidates) {
+				out.WriteString(strings.Repeat(" ", width-displayWidth(display)))
+			}
+		}
+		out.WriteString("\r\n")
+	}
+	return out.String()
+}
+
+// formatDescriptions выводит варианты с описаниями по одному в строке:
+// описания выравниваются в колонку и обрезаются по ширине терминала
+func (e *LineEditor) formatDescriptions(candidates []Candidate) string {
+	width := 0
+	for _, candidate := range candidates {
+		width = max(width, displayWidth(candidate.Display))
+	}
+	width = min(width, e.columns/2)
+
+	var out strings.Builder
+	for _, candidate := range candidates {
+		line := candidate.Display
+		if candidate.Description != "" {
+			padding := max(width-displayWidth(line), 0) + 2
+			line += strings.Repeat(" ", padding) + "(" + candidate.Description + ")"
+		}
+		out.WriteString(truncateWidth(line, e.columns-1))
+		out.WriteString("\r\n")
+	}
+	return out.String()
+}
+
+// hasDescriptions проверяет, есть ли описание хотя бы у одного варианта
+func hasDescriptions(candidates []Candidate) bool {
+	for _, candidate := range candidates {
+		if candidate.Description != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// bell подает звуковой сигнал, когда дополнить нечего
+func (e *LineEditor) bell() {
+	io.WriteString(e.out, "\a")
+}
+
+// commonPrefix возвращает общее начало текстов вариантов
+func commonPrefix(candidates []Candidate) string {
+	prefix := candidates[0].Value
+	for _, candidate := range candidates[1:] {
+		i := 0
+		for i < len(prefix) && i < len(candidate.Value) && prefix[i] == candidate.Value[i] {
+			i++
+		}
+		prefix = prefix[:i]
+	}
+	// Общее начало не должно обрывать многобайтовый символ
+	for len(prefix) > 0 && !utf8.ValidString(prefix) {
+		prefix = prefix[:len(prefix)-1]
+	}
+	return prefix
+}
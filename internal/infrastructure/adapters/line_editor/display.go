@@ -0,0 +1,99 @@
+package line_editor
+
+import (
+	"strings"
+	"unicode"
+)
+
+// displayWidth возвращает число колонок, которое строка занимает на экране;
+// escape-последовательности цвета вида \x1b[...m не учитываются
+func displayWidth(s string) int {
+	width := 0
+	inEscape := false
+	for _, r := range s {
+		switch {
+		case inEscape:
+			if r >= 0x40 && r <= 0x7e && r != '[' {
+				inEscape = false
+			}
+		case r == charEscape:
+			inEscape = true
+		default:
+			width += runeWidth(r)
+		}
+	}
+	return width
+}
+
+// runesWidth возвращает ширину символов при выводе через renderRunes
+func runesWidth(runes []rune) int {
+	width := 0
+	for _, r := range runes {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// runeWidth возвращает ширину символа: управляющие символы выводятся как ^X,
+// комбинируемые не занимают места, широкие восточноазиатские занимают две колонки
+func runeWidth(r rune) int {
+	switch {
+	case r < 0x20 || r == charBackspace:
+		return 2
+	case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r):
+		return 0
+	case isWideRune(r):
+		return 2
+	}
+	return 1
+}
+
+// isWideRune проверяет, относится ли символ к широким (CJK, эмодзи)
+func isWideRune(r rune) bool {
+	return (r >= 0x1100 && r <= 0x115f) ||
+		(r >= 0x2e80 && r <= 0xa4cf) ||
+		(r >= 0xac00 && r <= 0xd7a3) ||
+		(r >= 0xf900 && r <= 0xfaff) ||
+		(r >= 0xfe30 && r <= 0xfe4f) ||
+		(r >= 0xff00 && r <= 0xff60) ||
+		(r >= 0xffe0 && r <= 0xffe6) ||
+		(r >= 0x1f300 && r <= 0x1f64f) ||
+		(r >= 0x1f900 && r <= 0x1f9ff) ||
+		(r >= 0x20000 && r <= 0x3fffd)
+}
+
+// renderRunes форматирует символы строки для вывода: управляющие символы
+// показываются как ^X
+func renderRunes(runes []rune) string {
+	var out strings.Builder
+	for _, r := range runes {
+		switch {
+		case r == charBackspace:
+			out.WriteString("^?")
+		case r < 0x20:
+			out.WriteByte('^')
+			out.WriteRune(r + '@')
+		default:
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
+
+// truncateWidth обрезает строку до width колонок, заменяя отброшенный
+// конец многоточием
+func truncateWidth(s string, width int) string {
+	if displayWidth(s) <= width {
+		return s
+	}
+	var out strings.Builder
+	used := 0
+	for _, r := range s {
+		if used+runeWidth(r) > width-1 {
+			break
+		}
+		out.WriteRune(r)
+		used += runeWidth(r)
+	}
+	return out.String() + "…"
+}
@@ -0,0 +1,356 @@
+package line_editor
+
+import (
+	"io"
+	"unicode"
+)
+
+// emacsKeymap возвращает привязки клавиш в стиле Emacs, как в readline
+func emacsKeymap() map[key]editCommand {
+	return map[key]editCommand{
+		runeKey(ctrlM): acceptLine,
+		runeKey(ctrlJ): acceptLine,
+		runeKey(ctrlC): interruptLine,
+		runeKey(ctrlD): deleteCharOrEOF,
+		runeKey(ctrlL): clearScreen,
+
+		runeKey(ctrlA):               beginningOfLine,
+		runeKey(ctrlE):               endOfLine,
+		runeKey(ctrlB):               backwardChar,
+		runeKey(ctrlF):               forwardChar,
+		{code: keyHome}:              beginningOfLine,
+		{code: keyEnd}:               endOfLine,
+		{code: keyLeft}:              backwardChar,
+		{code: keyRight}:             forwardChar,
+		altKey('b'):                  backwardWord,
+		altKey('f'):                  forwardWord,
+		{code: keyLeft, ctrl: true}:  backwardWord,
+		{code: keyRight, ctrl: true}: forwardWord,
+		{code: keyLeft, alt: true}:   backwardWord,
+		{code: keyRight, alt: true}:  forwardWord,
+
+		{code: keyUp}:   previousHistory,
+		{code: keyDown}: nextHistory,
+		runeKey(ctrlP):  previousHistory,
+		runeKey(ctrlN):  nextHistory,
+		altKey('<'):     beginningOfHistory,
+		altKey('>'):     endOfHistory,
+		runeKey(ctrlR):  reverseSearchHistory,
+		runeKey(ctrlS):  forwardSearchHistory,
+
+		runeKey(ctrlI): complete,
+
+		runeKey(charBackspace): backwardDeleteChar,
+		runeKey(ctrlH):         backwardDeleteChar,
+		{code: keyDelete}:      deleteChar,
+		runeKey(ctrlT):         transposeChars,
+		altKey('u'):            upcaseWord,
+		altKey('l'):            downcaseWord,
+		altKey('c'):            capitalizeWord,
+		runeKey(ctrlV):         quotedInsert,
+
+		runeKey(ctrlK):        killLine,
+		runeKey(ctrlU):        unixLineDiscard,
+		runeKey(ctrlW):        unixWordRubout,
+		altKey('d'):           killWord,
+		altKey(charBackspace): backwardKillWord,
+		altKey(ctrlH):         backwardKillWord,
+		runeKey(ctrlY):        yank,
+		altKey('y'):           yankPop,
+
+		runeKey(ctrlUnderline): undo,
+		runeKey(ctrlX):         ctrlXPrefix,
+	}
+}
+
+// selfInsert вставляет введенный символ; подряд введенные символы
+// отменяются одним undo
+func selfInsert(e *LineEditor, k key) {
+	if e.lastAction != actionInsert {
+		e.saveUndo()
+	}
+	e.insert([]rune{k.r})
+	e.action = actionInsert
+	e.refresh()
+}
+
+// acceptLine завершает ввод строки
+func acceptLine(e *LineEditor, _ key) {
+	e.finish(nil)
+	io.WriteString(e.out, "\r\n")
+}
+
+// interruptLine отменяет ввод строки по Ctrl+C
+func interruptLine(e *LineEditor, _ key) {
+	e.finish(ErrInterrupted)
+	io.WriteString(e.out, "^C\r\n")
+}
+
+// deleteCharOrEOF удаляет символ под курсором, а на пустой строке
+// завершает ввод (Ctrl+D)
+func deleteCharOrEOF(e *LineEditor, k key) {
+	if len(e.buffer) == 0 {
+		e.finish(io.EOF)
+		io.WriteString(e.out, "\r\n")
+		return
+	}
+	deleteChar(e, k)
+}
+
+// clearScreen очищает экран и рисует строку в его верхней части
+func clearScreen(e *LineEditor, _ key) {
+	io.WriteString(e.out, "\x1b[H\x1b[2J")
+	e.cursorRow = 0
+	e.refresh()
+}
+
+// beginningOfLine переносит курсор в начало строки
+func beginningOfLine(e *LineEditor, _ key) {
+	e.cursor = 0
+	e.refresh()
+}
+
+// endOfLine переносит курсор в конец строки
+func endOfLine(e *LineEditor, _ key) {
+	e.cursor = len(e.buffer)
+	e.refresh()
+}
+
+// backwardChar сдвигает курсор на символ влево
+func backwardChar(e *LineEditor, _ key) {
+	if e.cursor > 0 {
+		e.cursor--
+		e.refresh()
+	}
+}
+
+// forwardChar сдвигает курсор на символ вправо; в конце строки
+// принимает подсказку из истории целиком
+func forwardChar(e *LineEditor, _ key) {
+	if e.acceptSuggestion(false) {
+		return
+	}
+	if e.cursor < len(e.buffer) {
+		e.cursor++
+		e.refresh()
+	}
+}
+
+// backwardWord переносит курсор в начало текущего или предыдущего слова
+func backwardWord(e *LineEditor, _ key) {
+	e.cursor = e.wordStart(e.cursor)
+	e.refresh()
+}
+
+// forwardWord переносит курсор в конец текущего или следующего слова;
+// в конце строки принимает следующее слово подсказки из истории
+func forwardWord(e *LineEditor, _ key) {
+	if e.acceptSuggestion(true) {
+		return
+	}
+	e.cursor = e.wordEnd(e.cursor)
+	e.refresh()
+}
+
+// backwardDeleteChar удаляет символ перед курсором
+func backwardDeleteChar(e *LineEditor, _ key) {
+	if e.cursor == 0 {
+		return
+	}
+	e.saveUndo()
+	e.remove(e.cursor-1, e.cursor)
+	e.refresh()
+}
+
+// deleteChar удаляет символ под курсором
+func deleteChar(e *LineEditor, _ key) {
+	if e.cursor == len(e.buffer) {
+		return
+	}
+	e.saveUndo()
+	e.remove(e.cursor, e.cursor+1)
+	e.refresh()
+}
+
+// transposeChars меняет местами символ перед курсором и символ под ним;
+// в конце строки - два последних символа
+func transposeChars(e *LineEditor, _ key) {
+	if len(e.buffer) < 2 || e.cursor == 0 {
+		return
+	}
+	e.saveUndo()
+	if e.cursor == len(e.buffer) {
+		e.cursor--
+	}
+	e.buffer[e.cursor-1], e.buffer[e.cursor] = e.buffer[e.cursor], e.buffer[e.cursor-1]
+	e.cursor++
+	e.refresh()
+}
+
+// upcaseWord переводит слово после курсора в верхний регистр
+func upcaseWord(e *LineEditor, _ key) {
+	e.changeWordCase(func(_ int, r rune) rune { return unicode.ToUpper(r) })
+}
+
+// downcaseWord переводит слово после курсора в нижний регистр
+func downcaseWord(e *LineEditor, _ key) {
+	e.changeWordCase(func(_ int, r rune) rune { return unicode.ToLower(r) })
+}
+
+// capitalizeWord делает первую букву слова после курсора заглавной, остальные - строчными
+func capitalizeWord(e *LineEditor, _ key) {
+	e.changeWordCase(func(i int, r rune) rune {
+		if i == 0 {
+			return unicode.ToUpper(r)
+		}
+		return unicode.ToLower(r)
+	})
+}
+
+// quotedInsert вставляет следующую клавишу как есть, даже управляющую (Ctrl+V)
+func quotedInsert(e *LineEditor, _ key) {
+	k, err := e.readNextKey()
+	if err != nil || k.code != keyRune {
+		return
+	}
+	e.saveUndo()
+	e.insert([]rune{k.r})
+	e.refresh()
+}
+
+// killLine удаляет текст от курсора до конца строки
+func killLine(e *LineEditor, _ key) {
+	e.kill(e.cursor, len(e.buffer), false)
+	e.refresh()
+}
+
+// unixLineDiscard удаляет текст от начала строки до курсора
+func unixLineDiscard(e *LineEditor, _ key) {
+	e.kill(0, e.cursor, true)
+	e.refresh()
+}
+
+// unixWordRubout удаляет слово до курсора, разделителями считаются пробелы
+func unixWordRubout(e *LineEditor, _ key) {
+	start := e.cursor
+	for start > 0 && unicode.IsSpace(e.buffer[start-1]) {
+		start--
+	}
+	for start > 0 && !unicode.IsSpace(e.buffer[start-1]) {
+		start--
+	}
+	e.kill(start, e.cursor, true)
+	e.refresh()
+}
+
+// killWord удаляет текст от курсора до конца слова
+func killWord(e *LineEditor, _ key) {
+	e.kill(e.cursor, e.wordEnd(e.cursor), false)
+	e.refresh()
+}
+
+// backwardKillWord удаляет текст от начала слова до курсора
+func backwardKillWord(e *LineEditor, _ key) {
+	e.kill(e.wordStart(e.cursor), e.cursor, true)
+	e.refresh()
+}
+
+// yank вставляет последний удаленный фрагмент
+func yank(e *LineEditor, _ key) {
+	text, ok := e.killRing.yank()
+	if !ok {
+		return
+	}
+	e.saveUndo()
+	e.yankStart = e.cursor
+	e.insert([]rune(text))
+	e.yankEnd = e.cursor
+	e.action = actionYank
+	e.refresh()
+}
+
+// yankPop заменяет только что вставленный фрагмент предыдущим из кольца удалений
+func yankPop(e *LineEditor, _ key) {
+	if e.lastAction != actionYank {
+		return
+	}
+	text, ok := e.killRing.rotate()
+	if !ok {
+		return
+	}
+	e.remove(e.yankStart, e.yankEnd)
+	e.cursor = e.yankStart
+	e.insert([]rune(text))
+	e.yankEnd = e.cursor
+	e.action = actionYank
+	e.refresh()
+}
+
+// undo отменяет последнее изменение строки
+func undo(e *LineEditor, _ key) {
+	if len(e.undoStack) == 0 {
+		return
+	}
+	state := e.undoStack[len(e.undoStack)-1]
+	e.undoStack = e.undoStack[:len(e.undoStack)-1]
+	e.buffer = state.buffer
+	e.cursor = state.cursor
+	e.refresh()
+}
+
+// ctrlXPrefix обрабатывает сочетания Ctrl+X: Ctrl+X Ctrl+U - undo
+func ctrlXPrefix(e *LineEditor, _ key) {
+	k, err := e.readNextKey()
+	if err != nil {
+		return
+	}
+	if k == runeKey(ctrlU) {
+		undo(e, k)
+	}
+}
+
+// changeWordCase меняет регистр слова после курсора и переносит курсор за него
+func (e *LineEditor) changeWordCase(convert func(i int, r rune) rune) {
+	end := e.wordEnd(e.cursor)
+	if end == e.cursor {
+		return
+	}
+	e.saveUndo()
+
+	i := 0
+	for pos := e.cursor; pos < end; pos++ {
+		if isWordRune(e.buffer[pos]) {
+			e.buffer[pos] = convert(i, e.buffer[pos])
+			i++
+		}
+	}
+	e.cursor = end
+	e.refresh()
+}
+
+// wordStart возвращает начало слова перед позицией pos
+func (e *LineEditor) wordStart(pos int) int {
+	for pos > 0 && !isWordRune(e.buffer[pos-1]) {
+		pos--
+	}
+	for pos > 0 && isWordRune(e.buffer[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+// wordEnd возвращает конец слова после позиции pos
+func (e *LineEditor) wordEnd(pos int) int {
+	for pos < len(e.buffer) && !isWordRune(e.buffer[pos]) {
+		pos++
+	}
+	for pos < len(e.buffer) && isWordRune(e.buffer[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// isWordRune проверяет, входит ли символ в слово для перемещения по словам
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
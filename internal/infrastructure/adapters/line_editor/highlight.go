@@ -0,0 +1,75 @@
+package line_editor
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// styleReset - escape-последовательность сброса цвета
+const styleReset = "\x1b[0m"
+
+// Highlight - фрагмент строки, выводимый заданным стилем
+type Highlight struct {
+	// Start и End - границы фрагмента в строке (в байтах)
+	Start int
+	End   int
+	// Style - параметры SGR escape-последовательности, например "1;32"
+	Style string
+}
+
+// Highlighter возвращает фрагменты строки для подсветки синтаксиса;
+// фрагменты, идущие позже, перекрывают предыдущие
+type Highlighter func(line string) []Highlight
+
+// SetHighlighter задает функцию подсветки синтаксиса вводимой строки
+func (e *LineEditor) SetHighlighter(highlighter Highlighter) {
+	e.highlighter = highlighter
+}
+
+// renderHighlighted форматирует строку для вывода, раскрашивая фрагменты,
+// которые вернула функция подсветки
+func (e *LineEditor) renderHighlighted() string {
+	line := string(e.buffer)
+	highlights := e.highlighter(line)
+	if len(highlights) == 0 {
+		return renderRunes(e.buffer)
+	}
+
+	// Смещения в байтах переводятся в номера символов
+	runeIndex := make([]int, len(line)+1)
+	index := 0
+	for offset, r := range line {
+		for i := 0; i < utf8.RuneLen(r); i++ {
+			runeIndex[offset+i] = index
+		}
+		index++
+	}
+	runeIndex[len(line)] = index
+
+	styles := make([]string, len(e.buffer))
+	for _, h := range highlights {
+		start, end := max(h.Start, 0), min(h.End, len(line))
+		for i := runeIndex[min(start, end)]; i < runeIndex[end]; i++ {
+			styles[i] = h.Style
+		}
+	}
+
+	var out strings.Builder
+	current := ""
+	for i := range e.buffer {
+		if styles[i] != current {
+			if current != "" {
+				out.WriteString(styleReset)
+			}
+			if styles[i] != "" {
+				out.WriteString("\x1b[" + styles[i] + "m")
+			}
+			current = styles[i]
+		}
+		out.WriteString(renderRunes(e.buffer[i : i+1]))
+	}
+	if current != "" {
+		out.WriteString(styleReset)
+	}
+	return out.String()
+}
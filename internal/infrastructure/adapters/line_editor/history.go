@@ -0,0 +1,80 @@
+package line_editor
+
+// historyState - перемещение по истории во время ввода строки. Изменения
+// записей сохраняются до конца ввода строки, сама история не меняется.
+type historyState struct {
+	// entries - записи истории от старых к новым
+	entries []string
+	// lines - просмотренные записи с изменениями; последний элемент -
+	// вводимая строка
+	lines [][]rune
+	// index - текущая запись; len(entries) - вводимая строка
+	index int
+}
+
+// SetHistory задает записи истории для перемещения стрелками, от старых к новым
+func (e *LineEditor) SetHistory(entries []string) {
+	e.history.entries = entries
+}
+
+// resetHistory возвращает перемещение по истории к вводимой строке
+func (e *LineEditor) resetHistory() {
+	e.history.lines = make([][]rune, len(e.history.entries)+1)
+	e.history.index = len(e.history.entries)
+}
+
+// moveHistory переходит к записи с номером index и показывает ее для
+// редактирования; возвращает false, если такой записи нет
+func (e *LineEditor) moveHistory(index int) bool {
+	if index < 0 || index > len(e.history.entries) || index == e.history.index {
+		return false
+	}
+
+	e.history.lines[e.history.index] = append([]rune{}, e.buffer...)
+	e.history.index = index
+
+	line := e.history.lines[index]
+	if line == nil {
+		line = []rune(e.history.entries[index])
+	}
+	e.buffer = append([]rune(nil), line...)
+	e.cursor = len(e.buffer)
+
+	// Изменения отменяются только до показанной записи; вставка в режиме
+	// vi продолжается от нее
+	e.undoStack = nil
+	if e.undoLocked {
+		e.undoLocked = false
+		e.saveUndo()
+		e.undoLocked = true
+	}
+	return true
+}
+
+// previousHistory показывает предыдущую запись истории
+func previousHistory(e *LineEditor, _ key) {
+	if e.moveHistory(e.history.index - 1) {
+		e.refresh()
+	}
+}
+
+// nextHistory показывает следующую запись истории
+func nextHistory(e *LineEditor, _ key) {
+	if e.moveHistory(e.history.index + 1) {
+		e.refresh()
+	}
+}
+
+// beginningOfHistory показывает самую старую запись истории
+func beginningOfHistory(e *LineEditor, _ key) {
+	if e.moveHistory(0) {
+		e.refresh()
+	}
+}
+
+// endOfHistory возвращается к вводимой строке
+func endOfHistory(e *LineEditor, _ key) {
+	if e.moveHistory(len(e.history.entries)) {
+		e.refresh()
+	}
+}
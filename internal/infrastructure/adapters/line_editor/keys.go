@@ -0,0 +1,247 @@
+package line_editor
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// readTimeout - время ожидания ввода в десятых долях секунды; за это время
+// отличается одиночный Esc от начала escape-последовательности
+const readTimeout = 1
+
+// keyCode - специальная клавиша; для обычных символов keyRune
+type keyCode int
+
+const (
+	keyRune keyCode = iota
+	keyEscape
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyHome
+	keyEnd
+	keyDelete
+	keyInsert
+	keyPageUp
+	keyPageDown
+	keyShiftTab
+	keyUnknown
+)
+
+// Управляющие символы клавиатуры
+const (
+	ctrlA         = 0x01
+	ctrlB         = 0x02
+	ctrlC         = 0x03
+	ctrlD         = 0x04
+	ctrlE         = 0x05
+	ctrlF         = 0x06
+	ctrlG         = 0x07
+	ctrlH         = 0x08
+	ctrlI         = 0x09
+	ctrlJ         = 0x0a
+	ctrlK         = 0x0b
+	ctrlL         = 0x0c
+	ctrlM         = 0x0d
+	ctrlN         = 0x0e
+	ctrlP         = 0x10
+	ctrlR         = 0x12
+	ctrlS         = 0x13
+	ctrlT         = 0x14
+	ctrlU         = 0x15
+	ctrlV         = 0x16
+	ctrlW         = 0x17
+	ctrlX         = 0x18
+	ctrlY         = 0x19
+	ctrlUnderline = 0x1f
+	charEscape    = 0x1b
+	charBackspace = 0x7f
+)
+
+// key - нажатая клавиша: символ или специальная клавиша, возможно с Alt
+// (Meta) или Ctrl для стрелок
+type key struct {
+	code keyCode
+	r    rune
+	alt  bool
+	ctrl bool
+}
+
+// runeKey создает клавишу обычного символа
+func runeKey(r rune) key {
+	return key{code: keyRune, r: r}
+}
+
+// altKey создает сочетание Alt с символом
+func altKey(r rune) key {
+	return key{code: keyRune, r: r, alt: true}
+}
+
+// isPrintable проверяет, вставляет ли клавиша символ в строку
+func (k key) isPrintable() bool {
+	return k.code == keyRune && !k.alt && k.r >= 0x20 && k.r != charBackspace
+}
+
+// keyReader читает клавиши из терминала в посимвольном режиме
+type keyReader struct {
+	fd int
+	// pending - прочитанные, но еще не разобранные байты
+	pending []byte
+}
+
+// newKeyReader создает читатель клавиш для файлового дескриптора
+func newKeyReader(fd int) *keyReader {
+	return &keyReader{fd: fd}
+}
+
+// readByte возвращает следующий байт; ok == false, если за readTimeout
+// ничего не введено
+func (r *keyReader) readByte() (b byte, ok bool, err error) {
+	if len(r.pending) > 0 {
+		b = r.pending[0]
+		r.pending = r.pending[1:]
+		return b, true, nil
+	}
+
+	var buf [64]byte
+	n, err := readInput(r.fd, buf[:])
+	if err != nil {
+		return 0, false, err
+	}
+	if n == 0 {
+		return 0, false, nil
+	}
+	r.pending = append(r.pending, buf[1:n]...)
+	return buf[0], true, nil
+}
+
+// readKey возвращает следующую клавишу; ok == false, если за readTimeout
+// ничего не введено
+func (r *keyReader) readKey() (key, bool, error) {
+	b, ok, err := r.readByte()
+	if err != nil || !ok {
+		return key{}, false, err
+	}
+
+	if b == charEscape {
+		k, err := r.readEscape()
+		return k, true, err
+	}
+
+	ch, err := r.readRune(b)
+	return runeKey(ch), true, err
+}
+
+// readRune дочитывает многобайтовый символ UTF-8, начинающийся с байта first
+func (r *keyReader) readRune(first byte) (rune, error) {
+	if first < utf8.RuneSelf {
+		return rune(first), nil
+	}
+
+	bytes := []byte{first}
+	for !utf8.FullRune(bytes) {
+		b, ok, err := r.readByte()
+		if err != nil {
+			return utf8.RuneError, err
+		}
+		if !ok {
+			break
+		}
+		bytes = append(bytes, b)
+	}
+
+	ch, _ := utf8.DecodeRune(bytes)
+	return ch, nil
+}
+
+// readEscape разбирает клавишу, начинающуюся с Esc: CSI-последовательность
+// стрелок и функциональных клавиш, Alt+символ или одиночный Esc
+func (r *keyReader) readEscape() (key, error) {
+	b, ok, err := r.readByte()
+	if err != nil {
+		return key{}, err
+	}
+	if !ok {
+		return key{code: keyEscape}, nil
+	}
+
+	switch b {
+	case '[', 'O':
+		return r.readControlSequence()
+	case charEscape:
+		return key{code: keyEscape}, nil
+	}
+
+	ch, err := r.readRune(b)
+	return altKey(ch), err
+}
+
+// readControlSequence разбирает последовательность вида ESC [ params final
+// или ESC O final
+func (r *keyReader) readControlSequence() (key, error) {
+	var params strings.Builder
+	for {
+		b, ok, err := r.readByte()
+		if err != nil {
+			return key{}, err
+		}
+		if !ok {
+			return key{code: keyUnknown}, nil
+		}
+		if b >= 0x40 && b <= 0x7e {
+			return decodeControlSequence(params.String(), b), nil
+		}
+		params.WriteByte(b)
+	}
+}
+
+// decodeControlSequence сопоставляет параметры и завершающий символ
+// последовательности с клавишей. Модификатор 5 означает Ctrl, 3 - Alt.
+func decodeControlSequence(params string, final byte) key {
+	fields := strings.Split(params, ";")
+	k := key{code: keyUnknown}
+
+	if len(fields) > 1 {
+		switch modifier, _ := strconv.Atoi(fields[1]); modifier {
+		case 3:
+			k.alt = true
+		case 5:
+			k.ctrl = true
+		}
+	}
+
+	switch final {
+	case 'A':
+		k.code = keyUp
+	case 'B':
+		k.code = keyDown
+	case 'C':
+		k.code = keyRight
+	case 'D':
+		k.code = keyLeft
+	case 'H':
+		k.code = keyHome
+	case 'F':
+		k.code = keyEnd
+	case 'Z':
+		k.code = keyShiftTab
+	case '~':
+		switch fields[0] {
+		case "1", "7":
+			k.code = keyHome
+		case "2":
+			k.code = keyInsert
+		case "3":
+			k.code = keyDelete
+		case "4", "8":
+			k.code = keyEnd
+		case "5":
+			k.code = keyPageUp
+		case "6":
+			k.code = keyPageDown
+		}
+	}
+	return k
+}
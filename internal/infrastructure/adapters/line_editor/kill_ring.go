@@ -0,0 +1,55 @@
+package line_editor
+
+// killRingSize - максимальное число фрагментов в кольце удалений
+const killRingSize = 32
+
+// killRing - кольцо удаленного текста для Ctrl+Y и Alt+Y
+type killRing struct {
+	entries []string
+	// index - фрагмент, вставленный последним Ctrl+Y или Alt+Y
+	index int
+}
+
+// push добавляет удаленный фрагмент; при merge он объединяется с последним
+// фрагментом (подряд идущие удаления), prepend - слева от него
+func (k *killRing) push(text string, merge, prepend bool) {
+	if text == "" {
+		return
+	}
+
+	if merge && len(k.entries) > 0 {
+		last := len(k.entries) - 1
+		if prepend {
+			k.entries[last] = text + k.entries[last]
+		} else {
+			k.entries[last] += text
+		}
+		return
+	}
+
+	k.entries = append(k.entries, text)
+	if len(k.entries) > killRingSize {
+		k.entries = k.entries[1:]
+	}
+}
+
+// yank возвращает последний удаленный фрагмент
+func (k *killRing) yank() (string, bool) {
+	if len(k.entries) == 0 {
+		return "", false
+	}
+	k.index = len(k.entries) - 1
+	return k.entries[k.index], true
+}
+
+// rotate возвращает фрагмент, удаленный перед текущим, по кругу
+func (k *killRing) rotate() (string, bool) {
+	if len(k.entries) == 0 {
+		return "", false
+	}
+	k.index--
+	if k.index < 0 {
+		k.index = len(k.entries) - 1
+	}
+	return k.entries[k.index], true
+}
@@ -0,0 +1,346 @@
+package line_editor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// defaultColumns - ширина терминала, если ее не удалось определить
+const defaultColumns = 80
+
+// ErrInterrupted возвращается ReadLine, если ввод строки прерван Ctrl+C
+var ErrInterrupted = errors.New("interrupted")
+
+// editAction - вид последней команды редактирования; от него зависит
+// объединение подряд идущих вставок в undo и удалений в кольце удалений
+type editAction int
+
+const (
+	actionOther editAction = iota
+	actionInsert
+	actionKill
+	actionYank
+	actionComplete
+)
+
+// editState - состояние строки для отмены изменений
+type editState struct {
+	buffer []rune
+	cursor int
+}
+
+// editCommand - команда редактирования, привязанная к клавише
+type editCommand func(e *LineEditor, k key)
+
+// EditingMode - режим привязок клавиш редактора
+type EditingMode int
+
+const (
+	EmacsMode EditingMode = iota
+	ViMode
+)
+
+// LineEditor - редактор строки ввода, работающий с терминалом
+// в посимвольном режиме
+type LineEditor struct {
+	fd      int
+	out     io.Writer
+	keys    *keyReader
+	resized chan os.Signal
+
+	prompt string
+	buffer []rune
+	cursor int
+	// cursorRow - строка экрана с курсором относительно первой строки приглашения
+	cursorRow int
+	columns   int
+
+	mode    EditingMode
+	keymap  map[key]editCommand
+	vi      viState
+	history historyState
+	search  *searchState
+	// lastSearch - последняя строка поиска; Ctrl+R с пустой строкой поиска
+	// ищет ее снова
+	lastSearch []rune
+	killRing   killRing
+	undoStack  []editState
+	// undoLocked - изменения не сохраняются в undo по отдельности: вставка
+	// в режиме vi отменяется целиком
+	undoLocked bool
+	// replay - клавиши, повторяемые командой . режима vi
+	replay []key
+	// completer - функция дополнения по Tab
+	completer Completer
+	// highlighter - функция подсветки синтаксиса
+	highlighter Highlighter
+	// suggester - функция подсказки продолжения строки, suggestion -
+	// показанная после курсора часть подсказки
+	suggester  Suggester
+	suggestion []rune
+
+	action     editAction
+	lastAction editAction
+	// yankStart и yankEnd - границы текста, вставленного последним Ctrl+Y
+	yankStart int
+	yankEnd   int
+
+	done bool
+	err  error
+}
+
+// NewLineEditor создает редактор, читающий клавиши из in и выводящий строку в out
+func NewLineEditor(in, out *os.File) *LineEditor {
+	e := &LineEditor{
+		fd:      int(in.Fd()),
+		out:     out,
+		keys:    newKeyReader(int(in.Fd())),
+		resized: make(chan os.Signal, 1),
+		keymap:  emacsKeymap(),
+	}
+	notifyResize(e.resized)
+	return e
+}
+
+// SetEditingMode переключает привязки клавиш между режимами emacs и vi
+func (e *LineEditor) SetEditingMode(mode EditingMode) {
+	e.mode = mode
+	if mode == ViMode {
+		e.keymap = viInsertKeymap()
+	} else {
+		e.keymap = emacsKeymap()
+	}
+}
+
+// ReadLine показывает приглашение и читает строку. При Ctrl+D на пустой
+// строке возвращает io.EOF, при Ctrl+C - ErrInterrupted.
+func (e *LineEditor) ReadLine(prompt string) (string, error) {
+	original, err := enableRawMode(e.fd)
+	if err != nil {
+		return "", err
+	}
+	defer restoreMode(e.fd, original)
+
+	e.reset(prompt)
+	if e.mode == ViMode {
+		defer io.WriteString(e.out, cursorDefault)
+	}
+	e.refresh()
+
+	for !e.done {
+		select {
+		case <-e.resized:
+			e.handleResize()
+		default:
+		}
+
+		k, ok, err := e.keys.readKey()
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			e.dispatch(k)
+		}
+	}
+
+	if e.err != nil {
+		return "", e.err
+	}
+	return string(e.buffer), nil
+}
+
+// reset готовит редактор к чтению новой строки
+func (e *LineEditor) reset(prompt string) {
+	e.prompt = prompt
+	e.buffer = nil
+	e.cursor = 0
+	e.cursorRow = 0
+	e.columns = terminalColumns(e.fd)
+	e.undoStack = nil
+	e.undoLocked = false
+	e.resetHistory()
+	e.search = nil
+	e.action = actionOther
+	e.lastAction = actionOther
+	e.done = false
+	e.err = nil
+
+	// Строка в режиме vi начинается в режиме вставки
+	if e.mode == ViMode {
+		e.vi.recording = false
+		e.enterInsertMode()
+	}
+}
+
+// dispatch выполняет команду, привязанную к клавише; печатные символы
+// без привязки вставляются в строку
+func (e *LineEditor) dispatch(k key) {
+	if e.search != nil {
+		e.searchKey(k)
+		return
+	}
+
+	if e.mode == ViMode {
+		// Быстро набранные Esc и символ читаются как Alt+символ
+		if k.alt && k.code == keyRune {
+			e.dispatch(key{code: keyEscape})
+			k.alt = false
+		}
+		e.vi.record(k)
+	}
+
+	e.action = actionOther
+	if e.mode == ViMode && e.vi.normal {
+		viCommand(e, k)
+	} else if command, ok := e.keymap[k]; ok {
+		command(e, k)
+	} else if k.isPrintable() {
+		selfInsert(e, k)
+	}
+	e.lastAction = e.action
+}
+
+// readNextKey ждет следующую клавишу для команд из нескольких нажатий
+func (e *LineEditor) readNextKey() (key, error) {
+	if len(e.replay) > 0 {
+		k := e.replay[0]
+		e.replay = e.replay[1:]
+		return k, nil
+	}
+
+	for {
+		k, ok, err := e.keys.readKey()
+		if err != nil || ok {
+			if ok {
+				e.vi.record(k)
+			}
+			return k, err
+		}
+	}
+}
+
+// finish завершает чтение строки с ошибкой err (nil - строка принята)
+func (e *LineEditor) finish(err error) {
+	// Строка перерисовывается уже без подсказки
+	e.done = true
+	e.cursor = len(e.buffer)
+	e.refresh()
+	e.err = err
+}
+
+// saveUndo запоминает состояние строки перед изменением
+func (e *LineEditor) saveUndo() {
+	if e.undoLocked {
+		return
+	}
+	e.undoStack = append(e.undoStack, editState{
+		buffer: append([]rune(nil), e.buffer...),
+		cursor: e.cursor,
+	})
+}
+
+// insert вставляет текст в позицию курсора
+func (e *LineEditor) insert(text []rune) {
+	buffer := make([]rune, 0, len(e.buffer)+len(text))
+	buffer = append(buffer, e.buffer[:e.cursor]...)
+	buffer = append(buffer, text...)
+	buffer = append(buffer, e.buffer[e.cursor:]...)
+	e.buffer = buffer
+	e.cursor += len(text)
+}
+
+// remove удаляет символы [start, end) и возвращает удаленный текст
+func (e *LineEditor) remove(start, end int) string {
+	removed := string(e.buffer[start:end])
+	e.buffer = append(e.buffer[:start:start], e.buffer[end:]...)
+	switch {
+	case e.cursor >= end:
+		e.cursor -= end - start
+	case e.cursor > start:
+		e.cursor = start
+	}
+	return removed
+}
+
+// kill удаляет символы [start, end) в кольцо удалений; подряд идущие
+// удаления объединяются в один фрагмент
+func (e *LineEditor) kill(start, end int, backward bool) {
+	if start >= end {
+		return
+	}
+	e.saveUndo()
+	e.killRing.push(e.remove(start, end), e.lastAction == actionKill, backward)
+	e.action = actionKill
+}
+
+// handleResize перерисовывает строку под новую ширину терминала
+func (e *LineEditor) handleResize() {
+	e.columns = terminalColumns(e.fd)
+	e.cursorRow = (displayWidth(e.prompt) + runesWidth(e.buffer[:e.cursor])) / e.columns
+	e.refresh()
+}
+
+// renderLine форматирует строку для вывода; текст, найденный поиском
+// по истории, выделяется, вне поиска строка раскрашивается подсветкой
+// синтаксиса
+func (e *LineEditor) renderLine() string {
+	if e.search == nil && e.highlighter != nil {
+		return e.renderHighlighted()
+	}
+	if e.search == nil {
+		return renderRunes(e.buffer)
+	}
+	start, end, ok := e.search.highlight(len(e.buffer))
+	if !ok {
+		return renderRunes(e.buffer)
+	}
+	return renderRunes(e.buffer[:start]) + highlightStart +
+		renderRunes(e.buffer[start:end]) + highlightEnd +
+		renderRunes(e.buffer[end:])
+}
+
+// refresh перерисовывает приглашение и строку с учетом переноса по ширине
+// терминала и ставит курсор на место
+func (e *LineEditor) refresh() {
+	var out strings.Builder
+
+	// Возвращаемся к первой строке приглашения и очищаем все ниже
+	if e.cursorRow > 0 {
+		fmt.Fprintf(&out, "\x1b[%dA", e.cursorRow)
+	}
+	prompt := e.prompt
+	if e.search != nil {
+		prompt = e.search.prompt()
+	}
+	out.WriteString("\r\x1b[J")
+	out.WriteString(prompt)
+	out.WriteString(e.renderLine())
+	e.updateSuggestion()
+	if len(e.suggestion) > 0 {
+		out.WriteString(suggestionStart + renderRunes(e.suggestion) + styleReset)
+	}
+
+	promptWidth := displayWidth(prompt)
+	total := promptWidth + runesWidth(e.buffer) + runesWidth(e.suggestion)
+	position := promptWidth + runesWidth(e.buffer[:e.cursor])
+
+	// Терминал не переводит строку, пока в нее не выведен следующий символ
+	if total > 0 && total%e.columns == 0 {
+		out.WriteString("\r\n")
+	}
+
+	row, col := position/e.columns, position%e.columns
+	if lastRow := total / e.columns; lastRow > row {
+		fmt.Fprintf(&out, "\x1b[%dA", lastRow-row)
+	}
+	out.WriteByte('\r')
+	if col > 0 {
+		fmt.Fprintf(&out, "\x1b[%dC", col)
+	}
+	e.cursorRow = row
+
+	io.WriteString(e.out, out.String())
+}
@@ -0,0 +1,222 @@
+package line_editor
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Escape-последовательности выделения найденного текста
+const (
+	highlightStart = "\x1b[7m"
+	highlightEnd   = "\x1b[27m"
+)
+
+// lineEnd - позиция начала поиска назад с конца строки
+const lineEnd = -1
+
+// searchState - состояние инкрементального поиска по истории (Ctrl+R, Ctrl+S)
+type searchState struct {
+	query   []rune
+	forward bool
+	failed  bool
+	// match - позиция найденного текста в показанной строке
+	match int
+	// originIndex, originBuffer и originCursor - строка до начала поиска,
+	// восстанавливаемая при отмене Ctrl+G
+	originIndex  int
+	originBuffer []rune
+	originCursor int
+}
+
+// reverseSearchHistory начинает поиск по истории от новых записей к старым (Ctrl+R)
+func reverseSearchHistory(e *LineEditor, _ key) {
+	e.startSearch(false)
+}
+
+// forwardSearchHistory начинает поиск по истории от старых записей к новым (Ctrl+S)
+func forwardSearchHistory(e *LineEditor, _ key) {
+	e.startSearch(true)
+}
+
+// startSearch переводит редактор в режим инкрементального поиска
+func (e *LineEditor) startSearch(forward bool) {
+	e.search = &searchState{
+		forward:      forward,
+		match:        e.cursor,
+		originIndex:  e.history.index,
+		originBuffer: append([]rune(nil), e.buffer...),
+		originCursor: e.cursor,
+	}
+	e.refresh()
+}
+
+// searchKey обрабатывает клавишу в режиме поиска: символы дополняют строку
+// поиска, Ctrl+R и Ctrl+S ищут следующее совпадение, Enter выполняет
+// найденную команду, Esc оставляет ее для редактирования, Ctrl+G отменяет
+// поиск. Остальные клавиши завершают поиск и выполняются как обычно.
+func (e *LineEditor) searchKey(k key) {
+	s := e.search
+
+	switch {
+	case k == runeKey(ctrlR), k == runeKey(ctrlS):
+		s.forward = k == runeKey(ctrlS)
+		if len(s.query) == 0 {
+			s.query = slices.Clone(e.lastSearch)
+			e.searchFrom(e.history.index, e.cursor)
+		} else {
+			e.searchNext()
+		}
+	case k == runeKey(charBackspace), k == runeKey(ctrlH):
+		if len(s.query) > 0 {
+			s.query = s.query[:len(s.query)-1]
+			e.restoreOrigin()
+			e.searchFrom(s.originIndex, s.originCursor)
+		}
+	case k == runeKey(ctrlG):
+		e.restoreOrigin()
+		e.search = nil
+	case k == key{code: keyEscape}:
+		e.endSearch()
+	case k.isPrintable():
+		s.query = append(s.query, k.r)
+		e.searchFrom(e.history.index, s.match)
+	default:
+		e.endSearch()
+		e.dispatch(k)
+		return
+	}
+	e.refresh()
+}
+
+// endSearch выходит из режима поиска, оставляя найденную строку
+func (e *LineEditor) endSearch() {
+	if len(e.search.query) > 0 {
+		e.lastSearch = e.search.query
+	}
+	e.search = nil
+}
+
+// restoreOrigin возвращает строку, бывшую до начала поиска
+func (e *LineEditor) restoreOrigin() {
+	s := e.search
+	e.moveHistory(s.originIndex)
+	e.buffer = append([]rune(nil), s.originBuffer...)
+	e.cursor = s.originCursor
+	s.match = s.originCursor
+	s.failed = false
+}
+
+// searchNext ищет следующее совпадение после текущего, пропуская
+// записи, совпадающие с показанной строкой
+func (e *LineEditor) searchNext() {
+	s := e.search
+	index, pos := e.history.index, s.match+1
+	if !s.forward {
+		pos = s.match - 1
+		if pos < 0 {
+			index, pos = index-1, lineEnd
+		}
+	}
+	current := string(e.buffer)
+
+	for {
+		foundIndex, foundPos, ok := e.findInHistory(s.query, index, pos, s.forward)
+		if !ok {
+			s.failed = true
+			return
+		}
+		if foundIndex == e.history.index || string(e.historyLine(foundIndex)) != current {
+			e.showMatch(foundIndex, foundPos)
+			return
+		}
+		index, pos = foundIndex+1, 0
+		if !s.forward {
+			index, pos = foundIndex-1, lineEnd
+		}
+	}
+}
+
+// searchFrom ищет строку поиска начиная с записи index и позиции pos включительно
+func (e *LineEditor) searchFrom(index, pos int) {
+	s := e.search
+	if len(s.query) == 0 {
+		s.failed = false
+		return
+	}
+
+	foundIndex, foundPos, ok := e.findInHistory(s.query, index, pos, s.forward)
+	if !ok {
+		s.failed = true
+		return
+	}
+	e.showMatch(foundIndex, foundPos)
+}
+
+// showMatch показывает запись с совпадением и ставит курсор на его начало
+func (e *LineEditor) showMatch(index, pos int) {
+	e.moveHistory(index)
+	e.cursor = pos
+	e.search.match = pos
+	e.search.failed = false
+}
+
+// findInHistory ищет query в записях истории начиная с записи index:
+// назад - в позициях не дальше pos (lineEnd - с конца строки), вперед -
+// не раньше pos
+func (e *LineEditor) findInHistory(query []rune, index, pos int, forward bool) (int, int, bool) {
+	for index >= 0 && index <= len(e.history.entries) {
+		line := e.historyLine(index)
+		if forward {
+			for i := max(pos, 0); i+len(query) <= len(line); i++ {
+				if slices.Equal(line[i:i+len(query)], query) {
+					return index, i, true
+				}
+			}
+			index, pos = index+1, 0
+		} else {
+			if pos == lineEnd || pos+len(query) > len(line) {
+				pos = len(line) - len(query)
+			}
+			for i := pos; i >= 0; i-- {
+				if slices.Equal(line[i:i+len(query)], query) {
+					return index, i, true
+				}
+			}
+			index, pos = index-1, lineEnd
+		}
+	}
+	return 0, 0, false
+}
+
+// historyLine возвращает запись истории index с изменениями, сделанными
+// во время ввода; для вводимой строки - текущее содержимое
+func (e *LineEditor) historyLine(index int) []rune {
+	switch {
+	case index == e.history.index:
+		return e.buffer
+	case e.history.lines[index] != nil:
+		return e.history.lines[index]
+	}
+	return []rune(e.history.entries[index])
+}
+
+// prompt возвращает приглашение режима поиска
+func (s *searchState) prompt() string {
+	direction := "reverse"
+	if s.forward {
+		direction = "forward"
+	}
+	failed := ""
+	if s.failed {
+		failed = "failed "
+	}
+	return fmt.Sprintf("(%s%s-i-search)`%s': ", failed, direction, renderRunes(s.query))
+}
+
+// highlight возвращает границы найденного текста в строке
+func (s *searchState) highlight(length int) (int, int, bool) {
+	if s.failed || len(s.query) == 0 || s.match+len(s.query) > length {
+		return 0, 0, false
+	}
+	return s.match, s.match + len(s.query), true
+}
@@ -0,0 +1,54 @@
+package line_editor
+
+import (
+	"slices"
+	"strings"
+)
+
+// suggestionStart - escape-последовательность тусклого текста подсказки
+const suggestionStart = "\x1b[90m"
+
+// Suggester возвращает строку, предлагаемую как продолжение введенной,
+// или пустую строку
+type Suggester func(line string) string
+
+// SetSuggester задает функцию подсказки продолжения строки
+func (e *LineEditor) SetSuggester(suggester Suggester) {
+	e.suggester = suggester
+}
+
+// updateSuggestion находит продолжение строки, которое показывается
+// тусклым текстом после курсора. Подсказка есть, только когда курсор
+// в конце непустой строки вне поиска и командного режима vi.
+func (e *LineEditor) updateSuggestion() {
+	e.suggestion = nil
+	if e.suggester == nil || e.done || e.search != nil || len(e.buffer) == 0 ||
+		e.cursor != len(e.buffer) || (e.mode == ViMode && e.vi.normal) {
+		return
+	}
+
+	line := string(e.buffer)
+	if suggested := e.suggester(line); len(suggested) > len(line) && strings.HasPrefix(suggested, line) {
+		e.suggestion = []rune(suggested[len(line):])
+	}
+}
+
+// acceptSuggestion вставляет показанную подсказку целиком или до конца
+// ее следующего слова; возвращает false, если подсказки нет
+func (e *LineEditor) acceptSuggestion(word bool) bool {
+	if len(e.suggestion) == 0 || e.cursor != len(e.buffer) {
+		return false
+	}
+
+	e.saveUndo()
+	full := append(slices.Clone(e.buffer), e.suggestion...)
+	end := len(full)
+	if word {
+		e.buffer = full
+		end = e.wordEnd(e.cursor)
+	}
+	e.buffer = full[:end]
+	e.cursor = end
+	e.refresh()
+	return true
+}
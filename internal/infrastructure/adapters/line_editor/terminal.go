@@ -0,0 +1,97 @@
+//go:build linux || darwin
+
+package line_editor
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"unsafe"
+)
+
+// terminalState - параметры терминала, сохраняемые на время посимвольного режима
+type terminalState = syscall.Termios
+
+// IsTerminal проверяет, связан ли файл с терминалом
+func IsTerminal(f *os.File) bool {
+	_, err := getTermios(int(f.Fd()))
+	return err == nil
+}
+
+// getTermios читает параметры терминала
+func getTermios(fd int) (*syscall.Termios, error) {
+	var termios syscall.Termios
+	if err := ioctl(fd, ioctlGetTermios, uintptr(unsafe.Pointer(&termios))); err != nil {
+		return nil, err
+	}
+	return &termios, nil
+}
+
+// setTermios устанавливает параметры терминала
+func setTermios(fd int, termios *syscall.Termios) error {
+	return ioctl(fd, ioctlSetTermios, uintptr(unsafe.Pointer(termios)))
+}
+
+// enableRawMode переводит терминал в посимвольный режим без эха и сигналов
+// от клавиатуры; read возвращает 0 байт, если за readTimeout ничего не введено.
+// Возвращает прежние параметры для restoreMode.
+func enableRawMode(fd int) (*terminalState, error) {
+	original, err := getTermios(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := *original
+	raw.Iflag &^= syscall.BRKINT | syscall.ICRNL | syscall.INPCK | syscall.ISTRIP | syscall.IXON
+	raw.Oflag &^= syscall.OPOST
+	raw.Cflag |= syscall.CS8
+	raw.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.IEXTEN | syscall.ISIG
+	raw.Cc[syscall.VMIN] = 0
+	raw.Cc[syscall.VTIME] = readTimeout
+
+	if err := setTermios(fd, &raw); err != nil {
+		return nil, err
+	}
+	return original, nil
+}
+
+// restoreMode возвращает терминалу параметры, сохраненные enableRawMode
+func restoreMode(fd int, termios *terminalState) error {
+	return setTermios(fd, termios)
+}
+
+// terminalColumns возвращает ширину терминала в символах
+func terminalColumns(fd int) int {
+	var size struct {
+		rows, cols, xpixel, ypixel uint16
+	}
+	if err := ioctl(fd, syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&size))); err != nil || size.cols == 0 {
+		return defaultColumns
+	}
+	return int(size.cols)
+}
+
+// ioctl выполняет системный вызов ioctl
+func ioctl(fd int, request, arg uintptr) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), request, arg)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// readInput читает доступные байты ввода; вызов, прерванный сигналом,
+// повторяется
+func readInput(fd int, buf []byte) (int, error) {
+	for {
+		n, err := syscall.Read(fd, buf)
+		if err != syscall.EINTR {
+			return n, err
+		}
+	}
+}
+
+// notifyResize подписывает канал на сигнал изменения размера терминала
+func notifyResize(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGWINCH)
+}
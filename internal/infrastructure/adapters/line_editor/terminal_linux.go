@@ -0,0 +1,11 @@
+//go:build linux || darwin
+
+package line_editor
+
+import "syscall"
+
+// Запросы ioctl для чтения и установки параметров терминала
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+)
@@ -0,0 +1,42 @@
+//go:build !linux && !darwin
+
+package line_editor
+
+import (
+	"errors"
+	"os"
+)
+
+// errNoTerminal возвращается, если посимвольный режим терминала не поддерживается
+var errNoTerminal = errors.New("line editing is not supported on this platform")
+
+// terminalState - параметры терминала; на этой платформе не используются
+type terminalState struct{}
+
+// IsTerminal всегда возвращает false: строки читаются без редактирования
+func IsTerminal(f *os.File) bool {
+	return false
+}
+
+// enableRawMode не поддерживается на этой платформе
+func enableRawMode(fd int) (*terminalState, error) {
+	return nil, errNoTerminal
+}
+
+// restoreMode ничего не делает на этой платформе
+func restoreMode(fd int, state *terminalState) error {
+	return nil
+}
+
+// terminalColumns возвращает ширину терминала по умолчанию
+func terminalColumns(fd int) int {
+	return defaultColumns
+}
+
+// readInput не поддерживается на этой платформе
+func readInput(fd int, buf []byte) (int, error) {
+	return 0, errNoTerminal
+}
+
+// notifyResize ничего не делает: сигнала изменения размера нет
+func notifyResize(c chan<- os.Signal) {}
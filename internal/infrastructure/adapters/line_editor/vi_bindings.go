@@ -0,0 +1,607 @@
+package line_editor
+
+import (
+	"io"
+	"unicode"
+)
+
+// Escape-последовательности формы курсора: вертикальная черта в режиме
+// вставки, блок в командном режиме, форма терминала по умолчанию
+const (
+	cursorBar     = "\x1b[6 q"
+	cursorBlock   = "\x1b[2 q"
+	cursorDefault = "\x1b[0 q"
+)
+
+// viState - состояние режима vi
+type viState struct {
+	// normal - командный режим; иначе режим вставки
+	normal bool
+	// register - текст, удаленный или скопированный последней командой
+	register string
+
+	// recording - идет запись клавиш изменяющей команды для повтора
+	recording bool
+	recorded  []key
+	replaying bool
+	// lastChange и lastCount - последняя изменяющая команда и ее счетчик
+	lastChange []key
+	lastCount  int
+
+	// lastFind - последний поиск f, F, t или T для повтора через ; и ,
+	lastFind     rune
+	lastFindChar rune
+}
+
+// record запоминает клавишу записываемой изменяющей команды
+func (v *viState) record(k key) {
+	if v.recording && !v.replaying {
+		v.recorded = append(v.recorded, k)
+	}
+}
+
+// finishRecording сохраняет записанную команду для повтора через .
+func (v *viState) finishRecording(count int) {
+	if v.recording && !v.replaying {
+		v.lastChange = v.recorded
+		v.lastCount = count
+	}
+	v.recording = false
+}
+
+// viInsertKeymap возвращает привязки режима вставки vi
+func viInsertKeymap() map[key]editCommand {
+	return map[key]editCommand{
+		runeKey(ctrlM): acceptLine,
+		runeKey(ctrlJ): acceptLine,
+		runeKey(ctrlC): interruptLine,
+		runeKey(ctrlD): deleteCharOrEOF,
+		runeKey(ctrlL): clearScreen,
+
+		{code: keyEscape}:      viCommandMode,
+		{code: keyHome}:        beginningOfLine,
+		{code: keyEnd}:         endOfLine,
+		{code: keyLeft}:        backwardChar,
+		{code: keyRight}:       forwardChar,
+		{code: keyUp}:          previousHistory,
+		{code: keyDown}:        nextHistory,
+		runeKey(ctrlR):         reverseSearchHistory,
+		runeKey(ctrlS):         forwardSearchHistory,
+		{code: keyDelete}:      deleteChar,
+		runeKey(ctrlH):         backwardDeleteChar,
+		runeKey(charBackspace): backwardDeleteChar,
+		runeKey(ctrlU):         unixLineDiscard,
+		runeKey(ctrlW):         unixWordRubout,
+		runeKey(ctrlV):         quotedInsert,
+		runeKey(ctrlI):         complete,
+	}
+}
+
+// viCommandMode переключает редактор из режима вставки в командный (Esc)
+func viCommandMode(e *LineEditor, _ key) {
+	e.enterNormalMode()
+	if e.cursor > 0 {
+		e.cursor--
+	}
+	e.refresh()
+}
+
+// enterInsertMode переключает редактор в режим вставки; вся вставка
+// отменяется одной командой u
+func (e *LineEditor) enterInsertMode() {
+	e.saveUndo()
+	e.undoLocked = true
+	e.vi.normal = false
+	io.WriteString(e.out, cursorBar)
+}
+
+// enterNormalMode переключает редактор в командный режим
+func (e *LineEditor) enterNormalMode() {
+	e.undoLocked = false
+	e.vi.normal = true
+	e.vi.finishRecording(e.vi.lastCount)
+	io.WriteString(e.out, cursorBlock)
+}
+
+// viCommand выполняет команду командного режима vi: [count] команда,
+// [count] оператор [count] перемещение или [count] перемещение
+func viCommand(e *LineEditor, k key) {
+	count := 0
+	for isCountDigit(k, count) {
+		count = count*10 + int(k.r-'0')
+		next, err := e.readNextKey()
+		if err != nil {
+			return
+		}
+		k = next
+	}
+
+	if !e.vi.replaying {
+		e.vi.recording = true
+		e.vi.recorded = []key{k}
+	}
+	changed := e.viExecute(k, count)
+
+	switch {
+	case !changed:
+		e.vi.recording = false
+	case e.vi.normal:
+		e.vi.finishRecording(count)
+	default:
+		// Команда перешла в режим вставки: запись продолжается до Esc
+		e.vi.lastCount = count
+	}
+
+	if e.done {
+		return
+	}
+	e.clampCursor()
+	e.refresh()
+}
+
+// isCountDigit проверяет, продолжает ли клавиша счетчик команды;
+// 0 без счетчика - перемещение в начало строки
+func isCountDigit(k key, count int) bool {
+	if k.code != keyRune || k.alt {
+		return false
+	}
+	return (k.r >= '1' && k.r <= '9') || (k.r == '0' && count > 0)
+}
+
+// viExecute выполняет команду со счетчиком count (0 - не задан) и сообщает,
+// изменила ли она строку
+func (e *LineEditor) viExecute(k key, count int) bool {
+	n := max(count, 1)
+
+	switch k {
+	case runeKey(ctrlM), runeKey(ctrlJ):
+		acceptLine(e, k)
+		return false
+	case runeKey(ctrlC):
+		interruptLine(e, k)
+		return false
+	case runeKey(ctrlD):
+		if len(e.buffer) == 0 {
+			deleteCharOrEOF(e, k)
+		}
+		return false
+	case runeKey(ctrlL):
+		clearScreen(e, k)
+		return false
+	case runeKey(ctrlR):
+		reverseSearchHistory(e, k)
+		return false
+	case runeKey(ctrlS):
+		forwardSearchHistory(e, k)
+		return false
+	}
+
+	switch k {
+	case key{code: keyUp}, runeKey('k'), runeKey('-'):
+		e.viMoveHistory(e.history.index - n)
+		return false
+	case key{code: keyDown}, runeKey('j'), runeKey('+'):
+		e.viMoveHistory(e.history.index + n)
+		return false
+	}
+
+	if k.code != keyRune {
+		if pos, _, ok := e.viMotion(k, n); ok {
+			e.cursor = pos
+		}
+		return false
+	}
+
+	switch k.r {
+	case 'i':
+		e.enterInsertMode()
+	case 'a':
+		if len(e.buffer) > 0 {
+			e.cursor++
+		}
+		e.enterInsertMode()
+	case 'I':
+		e.cursor = e.firstNonBlank()
+		e.enterInsertMode()
+	case 'A':
+		e.cursor = len(e.buffer)
+		e.enterInsertMode()
+
+	case 'd', 'c', 'y':
+		return e.viOperator(k.r, count)
+	case 'D':
+		return e.viDelete(e.cursor, len(e.buffer))
+	case 'C':
+		e.viChange(e.cursor, len(e.buffer))
+	case 'S':
+		e.viChange(0, len(e.buffer))
+	case 's':
+		e.viChange(e.cursor, min(e.cursor+n, len(e.buffer)))
+	case 'x':
+		return e.viDelete(e.cursor, min(e.cursor+n, len(e.buffer)))
+	case 'X':
+		return e.viDelete(max(e.cursor-n, 0), e.cursor)
+
+	case 'r':
+		return e.viReplace(n)
+	case '~':
+		return e.viToggleCase(n)
+	case 'p':
+		return e.viPut(n, true)
+	case 'P':
+		return e.viPut(n, false)
+
+	case 'u':
+		for range n {
+			undo(e, k)
+		}
+		return false
+	case '.':
+		e.viRepeat(count)
+		return false
+
+	default:
+		if pos, _, ok := e.viMotion(k, n); ok {
+			e.cursor = pos
+		}
+		return false
+	}
+	return true
+}
+
+// viMoveHistory показывает запись истории index, ограниченную границами
+// истории, и ставит курсор в начало строки
+func (e *LineEditor) viMoveHistory(index int) {
+	index = min(max(index, 0), len(e.history.entries))
+	if e.moveHistory(index) {
+		e.cursor = 0
+	}
+}
+
+// viOperator выполняет оператор d, c или y над диапазоном от курсора до
+// позиции перемещения; dd, cc и yy действуют на всю строку
+func (e *LineEditor) viOperator(op rune, count int) bool {
+	k, err := e.readNextKey()
+	if err != nil {
+		return false
+	}
+	motionCount := 0
+	for isCountDigit(k, motionCount) {
+		motionCount = motionCount*10 + int(k.r-'0')
+		if k, err = e.readNextKey(); err != nil {
+			return false
+		}
+	}
+	n := max(count, 1) * max(motionCount, 1)
+
+	start, end := 0, len(e.buffer)
+	if k != runeKey(op) {
+		// Как в vim, cw на слове изменяет его до конца, не захватывая пробелы
+		if op == 'c' && (k.r == 'w' || k.r == 'W') && k.code == keyRune &&
+			e.cursor < len(e.buffer) && !unicode.IsSpace(e.buffer[e.cursor]) {
+			k.r = 'e' + (k.r - 'w')
+		}
+
+		pos, inclusive, ok := e.viMotion(k, n)
+		if !ok {
+			return false
+		}
+		start, end = min(e.cursor, pos), max(e.cursor, pos)
+		if inclusive && end < len(e.buffer) {
+			end++
+		}
+	}
+
+	switch op {
+	case 'y':
+		e.vi.register = string(e.buffer[start:end])
+		e.cursor = start
+		return false
+	case 'c':
+		e.viChange(start, end)
+		return true
+	}
+	return e.viDelete(start, end)
+}
+
+// viMotion вычисляет позицию курсора после перемещения, повторенного n раз;
+// inclusive - символ в конечной позиции входит в диапазон оператора
+func (e *LineEditor) viMotion(k key, n int) (pos int, inclusive bool, ok bool) {
+	switch k.code {
+	case keyLeft:
+		return max(e.cursor-n, 0), false, true
+	case keyRight:
+		return min(e.cursor+n, len(e.buffer)), false, true
+	case keyHome:
+		return 0, false, true
+	case keyEnd:
+		return max(len(e.buffer)-1, 0), true, true
+	case keyRune:
+	default:
+		return 0, false, false
+	}
+
+	pos = e.cursor
+	switch k.r {
+	case 'h', charBackspace, ctrlH:
+		return max(pos-n, 0), false, true
+	case 'l', ' ':
+		return min(pos+n, len(e.buffer)), false, true
+	case '0':
+		return 0, false, true
+	case '^':
+		return e.firstNonBlank(), false, true
+	case '$':
+		return max(len(e.buffer)-1, 0), true, true
+	case '|':
+		return min(n-1, max(len(e.buffer)-1, 0)), false, true
+
+	case 'w', 'W':
+		for range n {
+			pos = e.viNextWordStart(pos, k.r == 'W')
+		}
+		return pos, false, true
+	case 'b', 'B':
+		for range n {
+			pos = e.viPrevWordStart(pos, k.r == 'B')
+		}
+		return pos, false, true
+	case 'e', 'E':
+		for range n {
+			pos = e.viWordEnd(pos, k.r == 'E')
+		}
+		return pos, true, true
+
+	case 'f', 'F', 't', 'T':
+		target, err := e.readNextKey()
+		if err != nil || !target.isPrintable() {
+			return 0, false, false
+		}
+		e.vi.lastFind, e.vi.lastFindChar = k.r, target.r
+		return e.viFind(k.r, target.r, n, false)
+	case ';', ',':
+		if e.vi.lastFind == 0 {
+			return 0, false, false
+		}
+		find := e.vi.lastFind
+		if k.r == ',' {
+			find = reverseFind(find)
+		}
+		return e.viFind(find, e.vi.lastFindChar, n, true)
+	}
+	return 0, false, false
+}
+
+// viFind ищет n-е вхождение символа target: f и t - вправо от курсора,
+// F и T - влево; t и T останавливаются перед символом. repeat - повтор
+// поиска командой ; или ,
+func (e *LineEditor) viFind(find, target rune, n int, repeat bool) (int, bool, bool) {
+	forward := find == 'f' || find == 't'
+	till := find == 't' || find == 'T'
+
+	pos := e.cursor
+	// Повтор t или T не должен застревать перед тем же символом
+	if till && repeat {
+		if forward && pos+1 < len(e.buffer) && e.buffer[pos+1] == target {
+			pos++
+		}
+		if !forward && pos > 0 && e.buffer[pos-1] == target {
+			pos--
+		}
+	}
+
+	for range n {
+		found := -1
+		if forward {
+			for i := pos + 1; i < len(e.buffer); i++ {
+				if e.buffer[i] == target {
+					found = i
+					break
+				}
+			}
+		} else {
+			for i := pos - 1; i >= 0; i-- {
+				if e.buffer[i] == target {
+					found = i
+					break
+				}
+			}
+		}
+		if found < 0 {
+			return 0, false, false
+		}
+		pos = found
+	}
+
+	switch {
+	case till && forward:
+		pos--
+	case till:
+		pos++
+	}
+	return pos, forward, true
+}
+
+// reverseFind возвращает поиск в обратном направлении для команды ,
+func reverseFind(find rune) rune {
+	switch find {
+	case 'f':
+		return 'F'
+	case 'F':
+		return 'f'
+	case 't':
+		return 'T'
+	}
+	return 't'
+}
+
+// viDelete удаляет символы [start, end) в регистр и сообщает, изменилась ли строка
+func (e *LineEditor) viDelete(start, end int) bool {
+	if start >= end {
+		return false
+	}
+	e.saveUndo()
+	e.vi.register = e.remove(start, end)
+	e.cursor = start
+	return true
+}
+
+// viChange удаляет символы [start, end) в регистр и переходит в режим вставки;
+// удаление и вставка отменяются вместе
+func (e *LineEditor) viChange(start, end int) {
+	e.saveUndo()
+	e.undoLocked = true
+	if start < end {
+		e.vi.register = e.remove(start, end)
+		e.cursor = start
+	}
+	e.enterInsertMode()
+}
+
+// viReplace заменяет n символов начиная с курсора следующим введенным символом
+func (e *LineEditor) viReplace(n int) bool {
+	k, err := e.readNextKey()
+	if err != nil || !k.isPrintable() || e.cursor+n > len(e.buffer) {
+		return false
+	}
+	e.saveUndo()
+	for i := range n {
+		e.buffer[e.cursor+i] = k.r
+	}
+	e.cursor += n - 1
+	return true
+}
+
+// viToggleCase меняет регистр n символов начиная с курсора и сдвигает курсор
+func (e *LineEditor) viToggleCase(n int) bool {
+	if e.cursor >= len(e.buffer) {
+		return false
+	}
+	e.saveUndo()
+	end := min(e.cursor+n, len(e.buffer))
+	for ; e.cursor < end; e.cursor++ {
+		r := e.buffer[e.cursor]
+		if unicode.IsUpper(r) {
+			e.buffer[e.cursor] = unicode.ToLower(r)
+		} else {
+			e.buffer[e.cursor] = unicode.ToUpper(r)
+		}
+	}
+	return true
+}
+
+// viPut вставляет содержимое регистра n раз после курсора (p) или перед ним (P);
+// курсор остается на последнем вставленном символе
+func (e *LineEditor) viPut(n int, after bool) bool {
+	if e.vi.register == "" {
+		return false
+	}
+	e.saveUndo()
+	if after && len(e.buffer) > 0 {
+		e.cursor++
+	}
+	text := []rune(e.vi.register)
+	for range n {
+		e.insert(text)
+	}
+	e.cursor--
+	return true
+}
+
+// viRepeat повторяет последнюю изменяющую команду; счетчик count,
+// если задан, заменяет счетчик исходной команды
+func (e *LineEditor) viRepeat(count int) {
+	if len(e.vi.lastChange) == 0 {
+		return
+	}
+	if count > 0 {
+		e.vi.lastCount = count
+	}
+
+	e.vi.replaying = true
+	defer func() { e.vi.replaying = false }()
+
+	e.replay = append([]key(nil), e.vi.lastChange[1:]...)
+	e.viExecute(e.vi.lastChange[0], e.vi.lastCount)
+	// Остаток записи - текст режима вставки и завершающий Esc
+	for len(e.replay) > 0 && !e.done {
+		k := e.replay[0]
+		e.replay = e.replay[1:]
+		e.dispatch(k)
+	}
+	e.replay = nil
+}
+
+// clampCursor не дает курсору командного режима выйти за последний символ
+func (e *LineEditor) clampCursor() {
+	if e.vi.normal && e.cursor >= len(e.buffer) {
+		e.cursor = max(len(e.buffer)-1, 0)
+	}
+}
+
+// firstNonBlank возвращает позицию первого непробельного символа строки
+func (e *LineEditor) firstNonBlank() int {
+	pos := 0
+	for pos < len(e.buffer) && unicode.IsSpace(e.buffer[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// viCharClass возвращает класс символа для перемещения по словам vi:
+// 0 - пробел, 1 - символ слова, 2 - прочие; для WORD (big) все
+// непробельные символы относятся к одному классу
+func viCharClass(r rune, big bool) int {
+	switch {
+	case unicode.IsSpace(r):
+		return 0
+	case big || isWordRune(r):
+		return 1
+	}
+	return 2
+}
+
+// viNextWordStart возвращает начало следующего слова (w, W)
+func (e *LineEditor) viNextWordStart(pos int, big bool) int {
+	if pos < len(e.buffer) {
+		class := viCharClass(e.buffer[pos], big)
+		for pos < len(e.buffer) && class != 0 && viCharClass(e.buffer[pos], big) == class {
+			pos++
+		}
+	}
+	for pos < len(e.buffer) && unicode.IsSpace(e.buffer[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// viPrevWordStart возвращает начало текущего или предыдущего слова (b, B)
+func (e *LineEditor) viPrevWordStart(pos int, big bool) int {
+	for pos > 0 && unicode.IsSpace(e.buffer[pos-1]) {
+		pos--
+	}
+	if pos == 0 {
+		return 0
+	}
+	class := viCharClass(e.buffer[pos-1], big)
+	for pos > 0 && viCharClass(e.buffer[pos-1], big) == class {
+		pos--
+	}
+	return pos
+}
+
+// viWordEnd возвращает конец текущего или следующего слова (e, E)
+func (e *LineEditor) viWordEnd(pos int, big bool) int {
+	pos++
+	for pos < len(e.buffer) && unicode.IsSpace(e.buffer[pos]) {
+		pos++
+	}
+	if pos >= len(e.buffer) {
+		return max(len(e.buffer)-1, 0)
+	}
+	class := viCharClass(e.buffer[pos], big)
+	for pos+1 < len(e.buffer) && viCharClass(e.buffer[pos+1], big) == class {
+		pos++
+	}
+	return pos
+}
@@ -19,6 +19,11 @@ func NewCommandExecutorAdapter(
 	}
 }
 
+// ExecutePipelines выполняет список пайплайнов
+func (e *CommandExecutorAdapter) ExecutePipelines(pipelines []*domain.Pipeline, ctx *domain.ExecutionContext) error {
+	return e.commandService.ExecutePipelines(pipelines, ctx)
+}
+
 // ExecutePipeline выполняет пайплайн команд
 func (e *CommandExecutorAdapter) ExecutePipeline(pipeline *domain.Pipeline, ctx *domain.ExecutionContext) error {
 	return e.commandService.ExecutePipeline(pipeline, ctx)
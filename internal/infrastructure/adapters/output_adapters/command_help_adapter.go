@@ -0,0 +1,103 @@
+package output_adapters
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// helpTimeout - наибольшее время выполнения команды с --help
+const helpTimeout = 2 * time.Second
+
+// defaultManPath - каталоги man-страниц, если MANPATH не задан
+var defaultManPath = []string{"/usr/local/share/man", "/usr/share/man", "/usr/local/man", "/usr/man"}
+
+// manSections - разделы man-страниц команд
+var manSections = []string{"1", "8", "6"}
+
+// CommandHelpAdapter - выходной адаптер для чтения справки внешних команд
+type CommandHelpAdapter struct{}
+
+// NewCommandHelpAdapter создает новый адаптер справки команд
+func NewCommandHelpAdapter() *CommandHelpAdapter {
+	return &CommandHelpAdapter{}
+}
+
+// ReadManPage возвращает исходный текст man-страницы команды из каталогов
+// manPath (через :) или стандартных каталогов; сжатые страницы
+// распаковываются
+func (a *CommandHelpAdapter) ReadManPage(name string, manPath string) (string, error) {
+	dirs := defaultManPath
+	if manPath != "" {
+		dirs = filepath.SplitList(manPath)
+	}
+
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		for _, section := range manSections {
+			page := filepath.Join(dir, "man"+section, name+"."+section)
+			if text, err := readManFile(page); err == nil {
+				return text, nil
+			}
+			if text, err := readManFile(page + ".gz"); err == nil {
+				return text, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no manual entry for %s", name)
+}
+
+// readManFile читает файл man-страницы, распаковывая .gz
+func readManFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return "", err
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// RunHelp запускает команду с --help без ввода и возвращает ее вывод;
+// команда, не завершившаяся за helpTimeout, прерывается
+func (a *CommandHelpAdapter) RunHelp(path string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), helpTimeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, "--help")
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("%s --help: %w", path, ctx.Err())
+	}
+	if output.Len() == 0 && err != nil {
+		return "", err
+	}
+	// Код возврата не важен: многие команды выводят справку с ошибкой
+	return output.String(), nil
+}
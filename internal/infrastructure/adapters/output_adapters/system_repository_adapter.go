@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"io"
 	"minishell/internal/domain"
+	"minishell/pkg/constants"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"syscall"
@@ -24,30 +26,33 @@ func NewSystemRepositoryAdapter() *SystemRepositoryAdapter {
 func (r *SystemRepositoryAdapter) ExecuteCommand(cmd *domain.Command, input []byte) ([]byte, int, error) {
 	// Проверяем, существует ли команда
 	if cmd.Name == "" {
-		return nil, 127, fmt.Errorf("command not found")
+		return nil, 127, domain.ErrCommandNotFound
 	}
 
-	// Проверяем, доступна ли команда в системе
-	if _, err := exec.LookPath(cmd.Name); err != nil {
-		return nil, 127, fmt.Errorf("command not found: %s", cmd.Name)
+	env := commandEnvironment(cmd)
+
+	// Команда ищется по PATH shell, а не процесса minishell
+	path, err := lookPath(cmd.Name, env)
+	if err != nil {
+		return nil, 127, err
 	}
 
-	execCmd := exec.Command(cmd.Name, cmd.Args...)
+	execCmd := &exec.Cmd{Path: path, Args: append([]string{cmd.Name}, cmd.Args...)}
 
 	var stdin io.Reader
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
 
-	// Настройка ввода
-	if input != nil {
-		stdin = bytes.NewReader(input)
-	} else if cmd.Input != "" {
+	// Настройка ввода: редирект важнее пайпа
+	if cmd.Input != "" {
 		file, err := os.Open(cmd.Input)
 		if err != nil {
 			return nil, 1, err
 		}
 		defer file.Close()
 		stdin = file
+	} else if input != nil {
+		stdin = bytes.NewReader(input)
 	} else {
 		stdin = os.Stdin
 	}
@@ -79,11 +84,10 @@ func (r *SystemRepositoryAdapter) ExecuteCommand(cmd *domain.Command, input []by
 		stdout.Reset()
 	}
 
-	execCmd.Env = os.Environ()
+	execCmd.Env = env
 
 	var exitCode int
-	err := execCmd.Run()
-	if err != nil {
+	if err := execCmd.Run(); err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			exitCode = exitErr.ExitCode()
 		} else {
@@ -102,6 +106,161 @@ func (r *SystemRepositoryAdapter) ExecuteCommand(cmd *domain.Command, input []by
 	return stdout.Bytes(), exitCode, nil
 }
 
+// StartPipeline запускает пайплайн внешних команд в фоне и возвращает PID
+// последней команды. Процессы пишут прямо в stdout и stderr shell.
+func (r *SystemRepositoryAdapter) StartPipeline(commands []*domain.Command) (int, error) {
+	var processes []*exec.Cmd
+	var files []*os.File
+
+	closeFiles := func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}
+
+	var stdin io.Reader
+	for i, cmd := range commands {
+		env := commandEnvironment(cmd)
+		path, err := lookPath(cmd.Name, env)
+		if err != nil {
+			closeFiles()
+			return 0, err
+		}
+
+		execCmd := &exec.Cmd{Path: path, Args: append([]string{cmd.Name}, cmd.Args...)}
+		execCmd.Env = env
+		execCmd.Stdin = stdin
+		execCmd.Stdout = os.Stdout
+		execCmd.Stderr = os.Stderr
+
+		if cmd.Input != "" {
+			file, err := os.Open(cmd.Input)
+			if err != nil {
+				closeFiles()
+				return 0, err
+			}
+			files = append(files, file)
+			execCmd.Stdin = file
+		}
+
+		if cmd.Output != "" {
+			flags := os.O_CREATE | os.O_WRONLY
+			if cmd.Append {
+				flags |= os.O_APPEND
+			} else {
+				flags |= os.O_TRUNC
+			}
+			file, err := os.OpenFile(cmd.Output, flags, 0644)
+			if err != nil {
+				closeFiles()
+				return 0, err
+			}
+			files = append(files, file)
+			execCmd.Stdout = file
+		} else if i < len(commands)-1 {
+			reader, writer, err := os.Pipe()
+			if err != nil {
+				closeFiles()
+				return 0, err
+			}
+			files = append(files, reader, writer)
+			execCmd.Stdout = writer
+			stdin = reader
+			processes = append(processes, execCmd)
+			continue
+		}
+
+		stdin = nil
+		processes = append(processes, execCmd)
+	}
+
+	for _, process := range processes {
+		if err := process.Start(); err != nil {
+			closeFiles()
+			return 0, err
+		}
+	}
+
+	// Дочерние процессы держат свои копии дескрипторов
+	closeFiles()
+
+	for _, process := range processes {
+		go process.Wait()
+	}
+
+	return processes[len(processes)-1].Process.Pid, nil
+}
+
+// commandEnvironment возвращает окружение для запуска команды: окружение
+// shell, дополненное присваиваниями перед командой (NAME=value cmd)
+func commandEnvironment(cmd *domain.Command) []string {
+	base := cmd.Environment
+	if base == nil {
+		base = os.Environ()
+	}
+	if len(cmd.Assignments) == 0 {
+		return base
+	}
+
+	overridden := make(map[string]bool, len(cmd.Assignments))
+	for _, assignment := range cmd.Assignments {
+		overridden[assignment.Name] = true
+	}
+
+	env := make([]string, 0, len(base)+len(cmd.Assignments))
+	for _, pair := range base {
+		name, _, _ := strings.Cut(pair, "=")
+		if !overridden[name] {
+			env = append(env, pair)
+		}
+	}
+	for _, assignment := range cmd.Assignments {
+		env = append(env, assignment.Name+"="+assignment.Value)
+	}
+	return env
+}
+
+// lookPath находит исполняемый файл команды: по пути, если имя содержит /,
+// иначе в каталогах PATH из окружения команды
+func lookPath(name string, env []string) (string, error) {
+	if strings.Contains(name, "/") {
+		if isExecutable(name) {
+			return name, nil
+		}
+		return "", fmt.Errorf("%w: %s", domain.ErrCommandNotFound, name)
+	}
+
+	searchPath, ok := environmentValue(env, "PATH")
+	if !ok {
+		searchPath = constants.DefaultPath
+	}
+	for _, dir := range filepath.SplitList(searchPath) {
+		if dir == "" {
+			dir = "."
+		}
+		if path := filepath.Join(dir, name); isExecutable(path) {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", domain.ErrCommandNotFound, name)
+}
+
+// environmentValue возвращает значение переменной из списка NAME=value
+func environmentValue(env []string, name string) (string, bool) {
+	for i := len(env) - 1; i >= 0; i-- {
+		if key, value, ok := strings.Cut(env[i], "="); ok && key == name {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// isExecutable проверяет, что путь указывает на исполняемый файл
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir() && info.Mode()&0111 != 0
+}
+
 // ChangeDirectory меняет текущую директорию
 func (r *SystemRepositoryAdapter) ChangeDirectory(path string) error {
 	return os.Chdir(path)
@@ -124,6 +283,93 @@ func (r *SystemRepositoryAdapter) GetEnvironment() map[string]string {
 	return env
 }
 
+// ReadFile читает содержимое файла
+func (r *SystemRepositoryAdapter) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+// WriteFile записывает данные в файл с перезаписью или добавлением
+func (r *SystemRepositoryAdapter) WriteFile(path string, data []byte, append bool) error {
+	flags := os.O_CREATE | os.O_WRONLY
+	if append {
+		flags |= os.O_APPEND
+	} else {
+		flags |= os.O_TRUNC
+	}
+
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(data)
+	return err
+}
+
+// WritePrivateFile перезаписывает файл, доступный только владельцу (0600);
+// права существующего файла тоже ограничиваются
+func (r *SystemRepositoryAdapter) WritePrivateFile(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := file.Chmod(0600); err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	return err
+}
+
+// ReadDirectory возвращает файлы каталога; для символических ссылок
+// сведения берутся о файле, на который они указывают
+func (r *SystemRepositoryAdapter) ReadDirectory(path string) ([]domain.FileInfo, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]domain.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, err := os.Stat(filepath.Join(path, entry.Name()))
+		if err != nil {
+			// Битая ссылка показывается как обычный файл
+			files = append(files, domain.FileInfo{Name: entry.Name()})
+			continue
+		}
+		files = append(files, domain.FileInfo{
+			Name:         entry.Name(),
+			IsDir:        info.IsDir(),
+			IsExecutable: !info.IsDir() && info.Mode()&0111 != 0,
+			Size:         info.Size(),
+			ModTime:      info.ModTime(),
+		})
+	}
+	return files, nil
+}
+
+// StatFile возвращает сведения о файле; символические ссылки разыменовываются
+func (r *SystemRepositoryAdapter) StatFile(path string) (domain.FileInfo, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return domain.FileInfo{}, err
+	}
+	return domain.FileInfo{
+		Name:         info.Name(),
+		IsDir:        info.IsDir(),
+		IsExecutable: !info.IsDir() && info.Mode()&0111 != 0,
+		Size:         info.Size(),
+		ModTime:      info.ModTime(),
+	}, nil
+}
+
+// CreateDirectory создает каталог вместе с недостающими родительскими
+func (r *SystemRepositoryAdapter) CreateDirectory(path string) error {
+	return os.MkdirAll(path, 0755)
+}
+
 // KillProcess убивает процесс по PID
 func (r *SystemRepositoryAdapter) KillProcess(pid int) error {
 	process, err := os.FindProcess(pid)
@@ -0,0 +1,301 @@
+package parser_adapters
+
+import (
+	"fmt"
+	"minishell/internal/domain"
+	"strconv"
+	"strings"
+)
+
+// maxArithmeticDepth ограничивает рекурсивное вычисление значений переменных
+const maxArithmeticDepth = 32
+
+// arithmeticOperators - бинарные операторы по возрастанию приоритета
+var arithmeticOperators = [][]string{
+	{"||"},
+	{"&&"},
+	{"|"},
+	{"^"},
+	{"&"},
+	{"==", "!="},
+	{"<=", ">=", "<", ">"},
+	{"<<", ">>"},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+// EvaluateArithmetic вычисляет целочисленное арифметическое выражение
+func (e *WordExpanderAdapter) EvaluateArithmetic(expr string, ctx *domain.ExecutionContext) (int, error) {
+	return e.evaluateArithmetic(expr, ctx, 0)
+}
+
+// evaluateArithmetic вычисляет выражение; depth - глубина вложенных
+// вычислений значений переменных
+func (e *WordExpanderAdapter) evaluateArithmetic(expr string, ctx *domain.ExecutionContext, depth int) (int, error) {
+	if depth > maxArithmeticDepth {
+		return 0, fmt.Errorf("%s: expression recursion level exceeded", expr)
+	}
+
+	ev := &arithmeticEvaluator{expander: e, ctx: ctx, input: expr, depth: depth}
+	ev.skipSpaces()
+	if ev.pos >= len(ev.input) {
+		return 0, nil
+	}
+
+	value, err := ev.parseBinary(0)
+	if err != nil {
+		return 0, err
+	}
+	ev.skipSpaces()
+	if ev.pos < len(ev.input) {
+		return 0, ev.syntaxError()
+	}
+	return value, nil
+}
+
+// arithmeticEvaluator - рекурсивный разбор арифметического выражения
+type arithmeticEvaluator struct {
+	expander *WordExpanderAdapter
+	ctx      *domain.ExecutionContext
+	input    string
+	pos      int
+	depth    int
+}
+
+// skipSpaces пропускает пробельные символы
+func (ev *arithmeticEvaluator) skipSpaces() {
+	for ev.pos < len(ev.input) && strings.IndexByte(" \t\n", ev.input[ev.pos]) >= 0 {
+		ev.pos++
+	}
+}
+
+// syntaxError формирует ошибку с остатком выражения, как это делает bash
+func (ev *arithmeticEvaluator) syntaxError() error {
+	return fmt.Errorf("%s: syntax error in expression (error token is \"%s\")", ev.input, ev.input[ev.pos:])
+}
+
+// matchOperator проверяет, начинается ли остаток выражения с оператора op,
+// не являющегося частью более длинного оператора
+func (ev *arithmeticEvaluator) matchOperator(op string) bool {
+	rest := ev.input[ev.pos:]
+	if !strings.HasPrefix(rest, op) {
+		return false
+	}
+	if len(rest) > len(op) {
+		next := rest[len(op)]
+		switch op {
+		case "<", ">":
+			return next != op[0] && next != '='
+		case "|", "&":
+			return next != op[0]
+		}
+	}
+	return true
+}
+
+// parseBinary разбирает бинарные операторы уровня level и выше
+func (ev *arithmeticEvaluator) parseBinary(level int) (int, error) {
+	if level == len(arithmeticOperators) {
+		return ev.parseUnary()
+	}
+
+	left, err := ev.parseBinary(level + 1)
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		ev.skipSpaces()
+		op := ""
+		for _, candidate := range arithmeticOperators[level] {
+			if ev.matchOperator(candidate) {
+				op = candidate
+				break
+			}
+		}
+		if op == "" {
+			return left, nil
+		}
+		ev.pos += len(op)
+
+		right, err := ev.parseBinary(level + 1)
+		if err != nil {
+			return 0, err
+		}
+		if left, err = ev.apply(op, left, right); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// apply применяет бинарный оператор
+func (ev *arithmeticEvaluator) apply(op string, left, right int) (int, error) {
+	switch op {
+	case "||":
+		return boolToInt(left != 0 || right != 0), nil
+	case "&&":
+		return boolToInt(left != 0 && right != 0), nil
+	case "|":
+		return left | right, nil
+	case "^":
+		return left ^ right, nil
+	case "&":
+		return left & right, nil
+	case "==":
+		return boolToInt(left == right), nil
+	case "!=":
+		return boolToInt(left != right), nil
+	case "<=":
+		return boolToInt(left <= right), nil
+	case ">=":
+		return boolToInt(left >= right), nil
+	case "<":
+		return boolToInt(left < right), nil
+	case ">":
+		return boolToInt(left > right), nil
+	case "<<":
+		return left << uint(right&63), nil
+	case ">>":
+		return left >> uint(right&63), nil
+	case "+":
+		return left + right, nil
+	case "-":
+		return left - right, nil
+	case "*":
+		return left * right, nil
+	}
+
+	if right == 0 {
+		return 0, fmt.Errorf("%s: division by 0 (error token is \"%s\")", ev.input, ev.input[ev.pos:])
+	}
+	if op == "/" {
+		return left / right, nil
+	}
+	return left % right, nil
+}
+
+// parseUnary разбирает унарные операторы + - ! ~
+func (ev *arithmeticEvaluator) parseUnary() (int, error) {
+	ev.skipSpaces()
+	if ev.pos >= len(ev.input) {
+		return 0, fmt.Errorf("%s: syntax error: operand expected (error token is \"%s\")", ev.input, ev.input[ev.pos:])
+	}
+
+	op := ev.input[ev.pos]
+	if strings.IndexByte("+-!~", op) < 0 {
+		return ev.parseOperand()
+	}
+
+	ev.pos++
+	value, err := ev.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	switch op {
+	case '-':
+		return -value, nil
+	case '!':
+		return boolToInt(value == 0), nil
+	case '~':
+		return ^value, nil
+	}
+	return value, nil
+}
+
+// parseOperand разбирает число, имя переменной или выражение в скобках
+func (ev *arithmeticEvaluator) parseOperand() (int, error) {
+	ch := ev.input[ev.pos]
+
+	switch {
+	case ch == '(':
+		ev.pos++
+		value, err := ev.parseBinary(0)
+		if err != nil {
+			return 0, err
+		}
+		ev.skipSpaces()
+		if ev.pos >= len(ev.input) || ev.input[ev.pos] != ')' {
+			return 0, fmt.Errorf("%s: missing `)' (error token is \"%s\")", ev.input, ev.input[ev.pos:])
+		}
+		ev.pos++
+		return value, nil
+
+	case isDigit(ch):
+		start := ev.pos
+		for ev.pos < len(ev.input) && (isNameChar(ev.input[ev.pos]) || ev.input[ev.pos] == '#') {
+			ev.pos++
+		}
+		return parseArithmeticNumber(ev.input[start:ev.pos])
+
+	case isNameStart(ch):
+		start := ev.pos
+		for ev.pos < len(ev.input) && isNameChar(ev.input[ev.pos]) {
+			ev.pos++
+		}
+		return ev.variableValue(ev.input[start:ev.pos])
+
+	case ch == '$':
+		// $name внутри выражения означает то же, что и name
+		nameLen := parameterNameLength(ev.input[ev.pos+1:])
+		if nameLen == 0 {
+			return 0, ev.syntaxError()
+		}
+		name := ev.input[ev.pos+1 : ev.pos+1+nameLen]
+		ev.pos += 1 + nameLen
+		return ev.variableValue(name)
+	}
+
+	return 0, ev.syntaxError()
+}
+
+// variableValue вычисляет значение переменной как выражение;
+// неустановленная или пустая переменная равна 0
+func (ev *arithmeticEvaluator) variableValue(name string) (int, error) {
+	value, err := ev.expander.requireParameter(name, ev.ctx)
+	if err != nil {
+		return 0, err
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		return n, nil
+	}
+	return ev.expander.evaluateArithmetic(value, ev.ctx, ev.depth+1)
+}
+
+// parseArithmeticNumber разбирает десятичное, восьмеричное (0NN),
+// шестнадцатеричное (0xNN) или число с основанием (base#NN)
+func parseArithmeticNumber(literal string) (int, error) {
+	base := 10
+	digits := literal
+
+	switch {
+	case strings.Contains(literal, "#"):
+		prefix, rest, _ := strings.Cut(literal, "#")
+		b, err := strconv.Atoi(prefix)
+		if err != nil || b < 2 || b > 36 {
+			return 0, fmt.Errorf("%s: invalid arithmetic base (error token is \"%s\")", literal, literal)
+		}
+		base, digits = b, rest
+	case strings.HasPrefix(literal, "0x") || strings.HasPrefix(literal, "0X"):
+		base, digits = 16, literal[2:]
+	case len(literal) > 1 && literal[0] == '0':
+		base, digits = 8, literal[1:]
+	}
+
+	n, err := strconv.ParseInt(digits, base, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: value too great for base (error token is \"%s\")", literal, literal)
+	}
+	return int(n), nil
+}
+
+// boolToInt превращает логическое значение в 1 или 0
+func boolToInt(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
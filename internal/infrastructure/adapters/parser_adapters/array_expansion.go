@@ -0,0 +1,120 @@
+package parser_adapters
+
+import (
+	"fmt"
+	"minishell/internal/domain"
+	"strconv"
+	"strings"
+)
+
+// splitSubscript отделяет имя массива и индекс в конструкции name[subscript]rest
+func splitSubscript(body string) (name, subscript, rest string, ok bool) {
+	n := identifierLength(body)
+	if n == 0 || !strings.HasPrefix(body[n:], "[") {
+		return "", "", "", false
+	}
+
+	end := findSubscriptEnd(body[n:])
+	if end <= 1 {
+		return "", "", "", false
+	}
+	return body[:n], body[n+1 : n+end], body[n+end+1:], true
+}
+
+// ExpandSubscript вычисляет ключ элемента массива: для ассоциативного массива
+// это раскрытая строка, для индексированного - неотрицательный индекс.
+// Отрицательный индекс отсчитывается от конца массива.
+func (e *WordExpanderAdapter) ExpandSubscript(name, subscript string, ctx *domain.ExecutionContext) (string, error) {
+	name = ctx.ResolveName(name)
+	expanded, err := e.expandSubword(subscript, ctx)
+	if err != nil {
+		return "", err
+	}
+
+	arr, isArray := ctx.GetArray(name)
+	if isArray && arr.Associative {
+		if expanded == "" {
+			return "", fmt.Errorf("%s[%s]: bad array subscript", name, subscript)
+		}
+		return expanded, nil
+	}
+
+	index, err := e.evalInteger(expanded, ctx)
+	if err != nil {
+		return "", err
+	}
+	if index < 0 {
+		switch {
+		case isArray:
+			index += arr.NextIndex()
+		case e.isScalarSet(name, ctx):
+			index++
+		}
+		if index < 0 {
+			return "", fmt.Errorf("%s[%s]: bad array subscript", name, subscript)
+		}
+	}
+
+	return strconv.Itoa(index), nil
+}
+
+// lookupElement возвращает элемент массива name[subscript]; для @ и *
+// элементы объединяются в одну строку. Скалярная переменная считается
+// массивом из одного элемента с индексом 0.
+func (e *WordExpanderAdapter) lookupElement(name, subscript string, ctx *domain.ExecutionContext) (string, bool, error) {
+	name = ctx.ResolveName(name)
+	if subscript == "@" || subscript == "*" {
+		separator := " "
+		if subscript == "*" {
+			separator = e.fieldSeparator(ctx)
+		}
+		values := e.arrayValues(name, ctx)
+		return strings.Join(values, separator), len(values) > 0, nil
+	}
+
+	key, err := e.ExpandSubscript(name, subscript, ctx)
+	if err != nil {
+		return "", false, err
+	}
+
+	if arr, ok := ctx.GetArray(name); ok {
+		value, set := arr.Get(key)
+		return value, set, nil
+	}
+	if key == "0" {
+		value, set := ctx.LookupEnv(name)
+		return value, set, nil
+	}
+	return "", false, nil
+}
+
+// arrayValues возвращает значения элементов массива в порядке ключей
+func (e *WordExpanderAdapter) arrayValues(name string, ctx *domain.ExecutionContext) []string {
+	name = ctx.ResolveName(name)
+	if arr, ok := ctx.GetArray(name); ok {
+		return arr.Values()
+	}
+	if value, ok := ctx.LookupEnv(name); ok {
+		return []string{value}
+	}
+	return nil
+}
+
+// arrayKeys возвращает ключи элементов массива для ${!name[@]}
+func (e *WordExpanderAdapter) arrayKeys(name string, ctx *domain.ExecutionContext) []string {
+	name = ctx.ResolveName(name)
+	if arr, ok := ctx.GetArray(name); ok {
+		return arr.Keys()
+	}
+	if e.isScalarSet(name, ctx) {
+		return []string{"0"}
+	}
+	return nil
+}
+
+// isScalarSet проверяет, установлена ли обычная (не массив) переменная
+func (e *WordExpanderAdapter) isScalarSet(name string, ctx *domain.ExecutionContext) bool {
+	name = ctx.ResolveName(name)
+	_, ok := ctx.LookupEnv(name)
+	return ok
+}
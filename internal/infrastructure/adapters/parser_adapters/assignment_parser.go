@@ -0,0 +1,140 @@
+package parser_adapters
+
+import (
+	"minishell/internal/domain"
+	"strings"
+)
+
+// ParseAssignment разбирает слово-присваивание NAME=value, NAME+=value,
+// NAME[subscript]=value или NAME=(elements); для других слов возвращает nil
+func (p *CommandParserAdapter) ParseAssignment(word string) (*domain.Assignment, error) {
+	return parseAssignmentWord(word)
+}
+
+// parseAssignmentWord разбирает слово-присваивание без снятия кавычек
+func parseAssignmentWord(word string) (*domain.Assignment, error) {
+	n := identifierLength(word)
+	if n == 0 {
+		return nil, nil
+	}
+	assignment := &domain.Assignment{Name: word[:n]}
+	rest := word[n:]
+
+	if strings.HasPrefix(rest, "[") {
+		end := findSubscriptEnd(rest)
+		if end <= 1 {
+			return nil, nil
+		}
+		assignment.Subscript = rest[1:end]
+		rest = rest[end+1:]
+	}
+
+	switch {
+	case strings.HasPrefix(rest, "+="):
+		assignment.Append = true
+		rest = rest[2:]
+	case strings.HasPrefix(rest, "="):
+		rest = rest[1:]
+	default:
+		return nil, nil
+	}
+
+	if !assignment.HasSubscript() && strings.HasPrefix(rest, "(") && strings.HasSuffix(rest, ")") {
+		elements, err := parseArrayElements(rest[1 : len(rest)-1])
+		if err != nil {
+			return nil, err
+		}
+		assignment.Compound = true
+		assignment.Elements = elements
+		return assignment, nil
+	}
+
+	assignment.Value = rest
+	return assignment, nil
+}
+
+// parseArrayElements разбивает содержимое составного присваивания на элементы
+func parseArrayElements(body string) ([]*domain.ArrayElement, error) {
+	tokens, err := newCommandLexer(body).tokenize()
+	if err != nil {
+		return nil, err
+	}
+
+	var elements []*domain.ArrayElement
+	for _, tok := range tokens {
+		switch tok.kind {
+		case tokenNewline, tokenEOF:
+			continue
+		case tokenOperator:
+			return nil, unexpectedToken(tok)
+		}
+
+		element := &domain.ArrayElement{Value: tok.value}
+		if strings.HasPrefix(tok.value, "[") {
+			if end := findSubscriptEnd(tok.value); end > 1 && strings.HasPrefix(tok.value[end+1:], "=") {
+				element.Subscript = tok.value[1:end]
+				element.Value = tok.value[end+2:]
+			}
+		}
+		elements = append(elements, element)
+	}
+
+	return elements, nil
+}
+
+// isCompoundAssignmentPrefix проверяет, что слово имеет вид NAME= или NAME+=
+// и за ним может следовать список (...)
+func isCompoundAssignmentPrefix(word string) bool {
+	name, ok := strings.CutSuffix(word, "=")
+	if !ok {
+		return false
+	}
+	name = strings.TrimSuffix(name, "+")
+	return name != "" && identifierLength(name) == len(name)
+}
+
+// identifierLength возвращает длину имени переменной в начале s
+func identifierLength(s string) int {
+	if s == "" || !isNameStart(s[0]) {
+		return 0
+	}
+	n := 1
+	for n < len(s) && isNameChar(s[n]) {
+		n++
+	}
+	return n
+}
+
+// findSubscriptEnd возвращает позицию скобки, закрывающей [ в начале s,
+// с учетом кавычек и вложенных скобок; -1, если скобка не найдена
+func findSubscriptEnd(s string) int {
+	depth := 0
+
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return -1
+			}
+			i += end + 1
+		case '"':
+			i = skipDoubleQuoted(s, i)
+		case '$':
+			if i+1 < len(s) && s[i+1] == '{' {
+				i = skipParameterBraces(s, i+1)
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
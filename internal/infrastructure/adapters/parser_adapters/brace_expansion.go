@@ -0,0 +1,262 @@
+package parser_adapters
+
+import (
+	"minishell/pkg/constants"
+	"strconv"
+	"strings"
+)
+
+// expandBraces выполняет подстановку фигурных скобок {a,b} и {1..10..2}.
+// Работает с исходным словом до остальных подстановок; скобки в кавычках,
+// экранированные скобки и конструкции ${...} не раскрываются.
+// Некорректные формы остаются без изменений.
+func expandBraces(word string) []string {
+	for open := 0; open < len(word); open++ {
+		open = nextBraceCandidate(word, open)
+		if open < 0 {
+			break
+		}
+
+		close, commas := findBraceClose(word, open)
+		if close < 0 {
+			continue
+		}
+
+		var alternatives []string
+		if len(commas) > 0 {
+			start := open + 1
+			for _, comma := range commas {
+				alternatives = append(alternatives, word[start:comma])
+				start = comma + 1
+			}
+			alternatives = append(alternatives, word[start:close])
+		} else if seq, ok := expandBraceSequence(word[open+1 : close]); ok {
+			alternatives = seq
+		} else {
+			continue
+		}
+
+		prefix := word[:open]
+		suffix := word[close+1:]
+
+		var result []string
+		for _, alt := range alternatives {
+			result = append(result, expandBraces(prefix+alt+suffix)...)
+		}
+		return result
+	}
+
+	return []string{word}
+}
+
+// nextBraceCandidate ищет следующую открывающую скобку вне кавычек,
+// начиная с позиции from; возвращает -1, если скобок больше нет
+func nextBraceCandidate(word string, from int) int {
+	for i := from; i < len(word); i++ {
+		switch word[i] {
+		case '\\':
+			i++
+		case '\'':
+			end := strings.IndexByte(word[i+1:], '\'')
+			if end < 0 {
+				return -1
+			}
+			i += end + 1
+		case '"':
+			i = skipDoubleQuoted(word, i)
+		case '$':
+			if i+1 < len(word) && word[i+1] == '{' {
+				i = skipParameterBraces(word, i+1)
+			}
+		case '{':
+			return i
+		}
+	}
+	return -1
+}
+
+// findBraceClose находит парную закрывающую скобку и позиции запятых верхнего уровня
+func findBraceClose(word string, open int) (int, []int) {
+	depth := 0
+	var commas []int
+
+	for i := open; i < len(word); i++ {
+		switch word[i] {
+		case '\\':
+			i++
+		case '\'':
+			end := strings.IndexByte(word[i+1:], '\'')
+			if end < 0 {
+				return -1, nil
+			}
+			i += end + 1
+		case '"':
+			i = skipDoubleQuoted(word, i)
+		case '$':
+			if i+1 < len(word) && word[i+1] == '{' {
+				i = skipParameterBraces(word, i+1)
+			}
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i, commas
+			}
+		case ',':
+			if depth == 1 {
+				commas = append(commas, i)
+			}
+		}
+	}
+
+	return -1, nil
+}
+
+// skipDoubleQuoted возвращает позицию закрывающей двойной кавычки
+func skipDoubleQuoted(word string, open int) int {
+	for i := open + 1; i < len(word); i++ {
+		switch word[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return len(word)
+}
+
+// skipParameterBraces возвращает позицию скобки, закрывающей ${...}
+func skipParameterBraces(word string, open int) int {
+	depth := 0
+	for i := open; i < len(word); i++ {
+		switch word[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(word)
+}
+
+// expandBraceSequence раскрывает последовательность x..y[..step]
+func expandBraceSequence(body string) ([]string, bool) {
+	parts := strings.Split(body, "..")
+	if len(parts) != 2 && len(parts) != 3 {
+		return nil, false
+	}
+
+	// Знак шага не важен: направление задают границы
+	step := uint64(1)
+	if len(parts) == 3 {
+		n, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, false
+		}
+		if n < 0 {
+			step = -uint64(n)
+		} else if n > 0 {
+			step = uint64(n)
+		}
+	}
+
+	if start, err := strconv.Atoi(parts[0]); err == nil {
+		end, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, false
+		}
+		return numericSequence(start, end, step, sequenceWidth(parts[0], parts[1]))
+	}
+
+	if isSequenceChar(parts[0]) && isSequenceChar(parts[1]) {
+		return charSequence(parts[0][0], parts[1][0], int(min(step, 256))), true
+	}
+
+	return nil, false
+}
+
+// sequenceWidth возвращает ширину дополнения нулями, если хотя бы одна
+// граница записана с ведущим нулем, например {01..10}
+func sequenceWidth(start, end string) int {
+	hasLeadingZero := func(s string) bool {
+		s = strings.TrimPrefix(s, "-")
+		return len(s) > 1 && s[0] == '0'
+	}
+
+	if !hasLeadingZero(start) && !hasLeadingZero(end) {
+		return 0
+	}
+	return max(len(start), len(end))
+}
+
+// numericSequence строит числовую последовательность. Число элементов
+// считается заранее в беззнаковой арифметике, чтобы границы около
+// math.MaxInt не переполняли счетчик; слишком длинная последовательность
+// (больше constants.MaxBraceSequence) не раскрывается.
+func numericSequence(start, end int, step uint64, width int) ([]string, bool) {
+	var distance uint64
+	if start <= end {
+		distance = uint64(end) - uint64(start)
+	} else {
+		distance = uint64(start) - uint64(end)
+	}
+
+	count := distance/step + 1
+	if count > constants.MaxBraceSequence {
+		return nil, false
+	}
+
+	result := make([]string, 0, count)
+	n := uint64(start)
+	for i := uint64(0); i < count; i++ {
+		result = append(result, padNumber(int(n), width))
+		if start <= end {
+			n += step
+		} else {
+			n -= step
+		}
+	}
+	return result, true
+}
+
+// padNumber форматирует число с дополнением нулями до заданной ширины
+func padNumber(n, width int) string {
+	s := strconv.Itoa(n)
+	if width == 0 {
+		return s
+	}
+
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		s = s[1:]
+	}
+	for len(sign)+len(s) < width {
+		s = "0" + s
+	}
+	return sign + s
+}
+
+// charSequence строит последовательность символов
+func charSequence(start, end byte, step int) []string {
+	if start > end {
+		step = -step
+	}
+
+	var result []string
+	for c := int(start); (step > 0 && c <= int(end)) || (step < 0 && c >= int(end)); c += step {
+		result = append(result, string(rune(c)))
+	}
+	return result
+}
+
+// isSequenceChar проверяет, является ли строка одной буквой
+func isSequenceChar(s string) bool {
+	return len(s) == 1 && ((s[0] >= 'a' && s[0] <= 'z') || (s[0] >= 'A' && s[0] <= 'Z'))
+}
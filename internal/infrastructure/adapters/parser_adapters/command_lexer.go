@@ -0,0 +1,268 @@
+package parser_adapters
+
+import "strings"
+
+// tokenKind - тип лексемы
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenOperator
+	tokenNewline
+	tokenEOF
+)
+
+// token - лексема командной строки
+type token struct {
+	kind  tokenKind
+	value string
+	// fromAliases - псевдонимы, при подстановке которых получена лексема;
+	// они не раскрываются повторно
+	fromAliases map[string]bool
+}
+
+// controlOperators - управляющие операторы, разделяющие слова
+var controlOperators = []string{"&&", "||", "|", "&", ";", "(", ")"}
+
+// commandLexer разбивает строку на лексемы, сохраняя кавычки внутри слов
+type commandLexer struct {
+	input string
+	pos   int
+}
+
+// newCommandLexer создает новый лексер
+func newCommandLexer(input string) *commandLexer {
+	return &commandLexer{input: input}
+}
+
+// tokenize возвращает все лексемы строки
+func (l *commandLexer) tokenize() ([]token, error) {
+	var tokens []token
+
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+		if tok.kind == tokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+// next возвращает следующую лексему
+func (l *commandLexer) next() (token, error) {
+	l.skipBlanks()
+
+	if l.pos >= len(l.input) {
+		return token{kind: tokenEOF}, nil
+	}
+
+	ch := l.input[l.pos]
+
+	switch {
+	case ch == '\n':
+		l.pos++
+		return token{kind: tokenNewline, value: "\n"}, nil
+
+	case ch == '#':
+		// Комментарий до конца строки
+		for l.pos < len(l.input) && l.input[l.pos] != '\n' {
+			l.pos++
+		}
+		return l.next()
+	}
+
+	for _, op := range controlOperators {
+		if strings.HasPrefix(l.input[l.pos:], op) {
+			l.pos += len(op)
+			return token{kind: tokenOperator, value: op}, nil
+		}
+	}
+
+	word, err := l.readWord()
+	if err != nil {
+		return token{}, err
+	}
+	return token{kind: tokenWord, value: word}, nil
+}
+
+// skipBlanks пропускает пробелы, табуляции и экранированные переводы строк
+func (l *commandLexer) skipBlanks() {
+	for l.pos < len(l.input) {
+		switch {
+		case l.input[l.pos] == ' ' || l.input[l.pos] == '\t':
+			l.pos++
+		case strings.HasPrefix(l.input[l.pos:], "\\\n"):
+			l.pos += 2
+		default:
+			return
+		}
+	}
+}
+
+// readWord читает слово целиком, не снимая кавычек
+func (l *commandLexer) readWord() (string, error) {
+	var word strings.Builder
+
+	for l.pos < len(l.input) {
+		ch := l.input[l.pos]
+
+		switch {
+		case ch == '(' && word.Len() > 0 && strings.IndexByte("?*+@!", word.String()[word.Len()-1]) >= 0:
+			// Группа extglob вида !(*.go) остается частью слова
+			group, err := l.readParenthesized()
+			if err != nil {
+				return "", err
+			}
+			word.WriteString(group)
+
+		case ch == '(' && isCompoundAssignmentPrefix(word.String()):
+			// Составное присваивание a=(x y z) остается одним словом
+			list, err := l.readParenthesized()
+			if err != nil {
+				return "", err
+			}
+			word.WriteString(list)
+
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '|' || ch == ';' || ch == '(' || ch == ')':
+			return word.String(), nil
+
+		case ch == '&':
+			// Оставляем в слове конструкции вида 2>&1
+			current := word.String()
+			if !strings.HasSuffix(current, ">") && !strings.HasSuffix(current, "<") {
+				return current, nil
+			}
+			word.WriteByte(ch)
+			l.pos++
+
+		case ch == '\\':
+			if l.pos+1 >= len(l.input) {
+				return "", &ParseError{Message: "unexpected EOF after `\\'", Incomplete: true}
+			}
+			if l.input[l.pos+1] == '\n' {
+				l.pos += 2
+				continue
+			}
+			word.WriteString(l.input[l.pos : l.pos+2])
+			l.pos += 2
+
+		case ch == '\'' || ch == '"':
+			quoted, err := l.readQuoted(ch)
+			if err != nil {
+				return "", err
+			}
+			word.WriteString(quoted)
+
+		case ch == '$' && strings.HasPrefix(l.input[l.pos:], "${"):
+			braced, err := l.readBraced()
+			if err != nil {
+				return "", err
+			}
+			word.WriteString(braced)
+
+		default:
+			word.WriteByte(ch)
+			l.pos++
+		}
+	}
+
+	return word.String(), nil
+}
+
+// readQuoted читает строку в кавычках вместе с самими кавычками
+func (l *commandLexer) readQuoted(quote byte) (string, error) {
+	start := l.pos
+	l.pos++
+
+	for l.pos < len(l.input) {
+		ch := l.input[l.pos]
+
+		switch {
+		case ch == quote:
+			l.pos++
+			return l.input[start:l.pos], nil
+		case ch == '\\' && quote == '"' && l.pos+1 < len(l.input):
+			l.pos += 2
+		case ch == '$' && quote == '"' && strings.HasPrefix(l.input[l.pos:], "${"):
+			if _, err := l.readBraced(); err != nil {
+				return "", err
+			}
+		default:
+			l.pos++
+		}
+	}
+
+	return "", &ParseError{
+		Message:    "unexpected EOF while looking for matching `" + string(quote) + "'",
+		Incomplete: true,
+	}
+}
+
+// readBraced читает конструкцию ${...} с учетом вложенности
+func (l *commandLexer) readBraced() (string, error) {
+	start := l.pos
+	l.pos += 2
+	depth := 1
+
+	for l.pos < len(l.input) {
+		ch := l.input[l.pos]
+
+		switch {
+		case ch == '\\' && l.pos+1 < len(l.input):
+			l.pos += 2
+		case ch == '\'' || ch == '"':
+			if _, err := l.readQuoted(ch); err != nil {
+				return "", err
+			}
+		case ch == '$' && strings.HasPrefix(l.input[l.pos:], "${"):
+			l.pos += 2
+			depth++
+		case ch == '}':
+			l.pos++
+			depth--
+			if depth == 0 {
+				return l.input[start:l.pos], nil
+			}
+		default:
+			l.pos++
+		}
+	}
+
+	return "", &ParseError{Message: "unexpected EOF while looking for matching `}'", Incomplete: true}
+}
+
+// readParenthesized читает конструкцию (...) с учетом вложенности и кавычек
+func (l *commandLexer) readParenthesized() (string, error) {
+	start := l.pos
+	l.pos++
+	depth := 1
+
+	for l.pos < len(l.input) {
+		ch := l.input[l.pos]
+
+		switch {
+		case ch == '\\' && l.pos+1 < len(l.input):
+			l.pos += 2
+		case ch == '\'' || ch == '"':
+			if _, err := l.readQuoted(ch); err != nil {
+				return "", err
+			}
+		case ch == '(':
+			l.pos++
+			depth++
+		case ch == ')':
+			l.pos++
+			depth--
+			if depth == 0 {
+				return l.input[start:l.pos], nil
+			}
+		default:
+			l.pos++
+		}
+	}
+
+	return "", &ParseError{Message: "unexpected EOF while looking for matching `)'", Incomplete: true}
+}
@@ -1,9 +1,8 @@
 package parser_adapters
 
 import (
+	"errors"
 	"minishell/internal/domain"
-	"minishell/pkg/utils"
-	"os"
 	"strings"
 )
 
@@ -15,126 +14,293 @@ func NewCommandParserAdapter() *CommandParserAdapter {
 	return &CommandParserAdapter{}
 }
 
-// Parse разбирает строку команды на пайплайны
-func (p *CommandParserAdapter) Parse(input string, env map[string]string) ([]*domain.Pipeline, error) {
-	input = strings.TrimSpace(input)
-	if input == "" {
+// Parse разбирает строку команды на пайплайны, раскрывая псевдонимы.
+// Подстановка переменных выполняется позже, в момент запуска команды.
+func (p *CommandParserAdapter) Parse(input string, aliases map[string]string) ([]*domain.Pipeline, error) {
+	if strings.TrimSpace(input) == "" {
 		return nil, nil
 	}
 
+	tokens, err := newCommandLexer(input).tokenize()
+	if err != nil {
+		return nil, err
+	}
+
+	ps := &commandParser{tokens: tokens, aliases: aliases, aliasNext: -1}
+	return ps.parseList(false)
+}
+
+// IsComplete проверяет, завершен ли ввод или требуется продолжение строки
+func (p *CommandParserAdapter) IsComplete(input string) bool {
+	_, err := p.Parse(input, nil)
+
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		return !parseErr.Incomplete
+	}
+	return true
+}
+
+// commandParser - рекурсивный разбор последовательности лексем
+type commandParser struct {
+	tokens  []token
+	pos     int
+	aliases map[string]string
+	// aliasNext - позиция слова, которое тоже проверяется на псевдоним,
+	// так как предыдущий псевдоним заканчивался пробелом
+	aliasNext int
+}
+
+// peek возвращает текущую лексему
+func (ps *commandParser) peek() token {
+	return ps.tokens[ps.pos]
+}
+
+// advance возвращает текущую лексему и переходит к следующей
+func (ps *commandParser) advance() token {
+	tok := ps.tokens[ps.pos]
+	if tok.kind != tokenEOF {
+		ps.pos++
+	}
+	return tok
+}
+
+// isOperator проверяет, является ли текущая лексема заданным оператором
+func (ps *commandParser) isOperator(op string) bool {
+	tok := ps.peek()
+	return tok.kind == tokenOperator && tok.value == op
+}
+
+// isWord проверяет, является ли текущая лексема заданным словом
+func (ps *commandParser) isWord(word string) bool {
+	tok := ps.peek()
+	return tok.kind == tokenWord && tok.value == word
+}
+
+// skipNewlines пропускает переводы строк
+func (ps *commandParser) skipNewlines() {
+	for ps.peek().kind == tokenNewline {
+		ps.advance()
+	}
+}
+
+// parseList разбирает список пайплайнов, разделенных ; & && || и переводами строк
+func (ps *commandParser) parseList(inGroup bool) ([]*domain.Pipeline, error) {
 	var pipelines []*domain.Pipeline
+	operator := ""
+
+	for {
+		ps.skipNewlines()
 
-	parts := p.splitByLogicalOperators(input)
+		if ps.peek().kind == tokenEOF {
+			if inGroup {
+				return nil, &ParseError{Message: "unexpected EOF while looking for matching `}'", Incomplete: true}
+			}
+			return pipelines, nil
+		}
 
-	// Первый пайплайн всегда выполняется
-	if len(parts) > 0 {
-		pipeline, err := p.parsePipeline(parts[0], env)
+		if inGroup && ps.isWord("}") {
+			ps.advance()
+			return pipelines, nil
+		}
+
+		pipeline, err := ps.parsePipeline()
 		if err != nil {
 			return nil, err
 		}
+		pipeline.SetOperator(operator)
 		pipelines = append(pipelines, pipeline)
-	}
 
-	// Обрабатываем остальные пайплайны с операторами
-	for i := 1; i < len(parts); i += 2 {
-		if i+1 >= len(parts) {
-			return nil, &ParseError{"missing command after operator"}
+		tok := ps.peek()
+		switch {
+		case tok.kind == tokenOperator && (tok.value == "&&" || tok.value == "||"):
+			ps.advance()
+			ps.skipNewlines()
+			if ps.peek().kind == tokenEOF {
+				return nil, &ParseError{Message: "missing command after operator", Incomplete: true}
+			}
+			if ps.peek().kind == tokenOperator || (inGroup && ps.isWord("}")) {
+				return nil, unexpectedToken(ps.peek())
+			}
+			operator = tok.value
+
+		case tok.kind == tokenOperator && tok.value == "&":
+			ps.advance()
+			for _, cmd := range pipeline.Commands {
+				cmd.Background = true
+			}
+			operator = ";"
+
+		case tok.kind == tokenOperator && tok.value == ";":
+			ps.advance()
+			operator = ";"
+
+		case tok.kind == tokenNewline || tok.kind == tokenEOF:
+			operator = ";"
+
+		default:
+			return nil, unexpectedToken(tok)
 		}
+	}
+}
 
-		operator := parts[i]
-		pipelineStr := parts[i+1]
+// parsePipeline разбирает пайплайн команд
+func (ps *commandParser) parsePipeline() (*domain.Pipeline, error) {
+	pipeline := domain.NewPipeline()
 
-		pipeline, err := p.parsePipeline(pipelineStr, env)
+	for {
+		cmd, err := ps.parseCommand()
 		if err != nil {
 			return nil, err
 		}
+		pipeline.AddCommand(cmd)
 
-		pipeline.SetOperator(operator)
-		pipelines = append(pipelines, pipeline)
+		if !ps.isOperator("|") {
+			return pipeline, nil
+		}
+		ps.advance()
+		ps.skipNewlines()
+		if ps.peek().kind == tokenEOF {
+			return nil, &ParseError{Message: "missing command after pipe", Incomplete: true}
+		}
 	}
-
-	return pipelines, nil
 }
 
-// splitByLogicalOperators разделяет строку по && и ||
-func (p *CommandParserAdapter) splitByLogicalOperators(input string) []string {
-	var parts []string
-	var current strings.Builder
-	inQuotes := false
-	quoteChar := byte(' ')
-
-	for i := 0; i < len(input); i++ {
-		ch := input[i]
+// parseCommand разбирает одну команду или определение функции
+func (ps *commandParser) parseCommand() (*domain.Command, error) {
+	if err := ps.expandAliases(); err != nil {
+		return nil, err
+	}
 
-		if ch == '"' || ch == '\'' {
-			if !inQuotes {
-				inQuotes = true
-				quoteChar = ch
-			} else if ch == quoteChar {
-				inQuotes = false
+	if ps.isWord("function") {
+		ps.advance()
+		tok := ps.advance()
+		if tok.kind != tokenWord {
+			return nil, unexpectedToken(tok)
+		}
+		if ps.isOperator("(") {
+			ps.advance()
+			if !ps.isOperator(")") {
+				return nil, unexpectedToken(ps.peek())
 			}
+			ps.advance()
 		}
+		return ps.parseFunctionBody(tok.value)
+	}
 
-		if !inQuotes && i < len(input)-1 {
-			op := input[i : i+2]
-			if op == "&&" || op == "||" {
-				if current.Len() > 0 {
-					parts = append(parts, utils.TrimSpace(current.String()))
-					parts = append(parts, op)
-					current.Reset()
-					i++
-					continue
-				}
-			}
+	if ps.peek().kind == tokenWord && ps.tokens[ps.pos+1].kind == tokenOperator && ps.tokens[ps.pos+1].value == "(" {
+		name := ps.advance().value
+		ps.advance()
+		if !ps.isOperator(")") {
+			return nil, unexpectedToken(ps.peek())
 		}
+		ps.advance()
+		return ps.parseFunctionBody(name)
+	}
+
+	return ps.parseSimpleCommand()
+}
 
-		current.WriteByte(ch)
+// expandAliases раскрывает псевдонимы в текущей позиции, пока это возможно
+func (ps *commandParser) expandAliases() error {
+	for {
+		expanded, err := ps.expandAlias()
+		if err != nil || !expanded {
+			return err
+		}
 	}
+}
 
-	if current.Len() > 0 {
-		parts = append(parts, utils.TrimSpace(current.String()))
+// expandAlias подставляет лексемы псевдонима вместо текущего слова
+func (ps *commandParser) expandAlias() (bool, error) {
+	tok := ps.peek()
+	if tok.kind != tokenWord || tok.fromAliases[tok.value] || strings.ContainsAny(tok.value, "'\"\\") {
+		return false, nil
 	}
 
-	return parts
-}
+	value, ok := ps.aliases[tok.value]
+	if !ok {
+		return false, nil
+	}
 
-// parsePipeline разбирает пайплайн команд
-func (p *CommandParserAdapter) parsePipeline(pipelineStr string, env map[string]string) (*domain.Pipeline, error) {
-	commands := utils.SplitFields(pipelineStr, "|")
-	pipeline := domain.NewPipeline()
+	lexed, err := newCommandLexer(value).tokenize()
+	if err != nil {
+		return false, err
+	}
+	lexed = lexed[:len(lexed)-1]
 
-	for _, cmdStr := range commands {
-		cmd, err := p.parseCommand(utils.TrimSpace(cmdStr), env)
-		if err != nil {
-			return nil, err
-		}
-		pipeline.AddCommand(cmd)
+	fromAliases := map[string]bool{tok.value: true}
+	for name := range tok.fromAliases {
+		fromAliases[name] = true
 	}
+	for i := range lexed {
+		lexed[i].fromAliases = fromAliases
+	}
+
+	rest := append([]token{}, ps.tokens[ps.pos+1:]...)
+	ps.tokens = append(append(ps.tokens[:ps.pos:ps.pos], lexed...), rest...)
 
-	return pipeline, nil
+	if ps.aliasNext > ps.pos {
+		ps.aliasNext += len(lexed) - 1
+	}
+	if strings.HasSuffix(value, " ") || strings.HasSuffix(value, "\t") {
+		ps.aliasNext = ps.pos + len(lexed)
+	}
+
+	return true, nil
 }
 
-// parseCommand разбирает одну команду
-func (p *CommandParserAdapter) parseCommand(cmdStr string, env map[string]string) (*domain.Command, error) {
-	tokens := p.tokenize(cmdStr)
-	if len(tokens) == 0 {
-		return nil, nil
+// parseFunctionBody разбирает тело функции в фигурных скобках
+func (ps *commandParser) parseFunctionBody(name string) (*domain.Command, error) {
+	if !isValidFunctionName(name) {
+		return nil, &ParseError{Message: "`" + name + "': not a valid identifier"}
+	}
+
+	ps.skipNewlines()
+	if ps.peek().kind == tokenEOF {
+		return nil, &ParseError{Message: "missing function body", Incomplete: true}
+	}
+	if !ps.isWord("{") {
+		return nil, unexpectedToken(ps.peek())
 	}
+	ps.advance()
 
-	cmd := domain.NewCommand(p.expandVariables(tokens[0], env))
-	i := 1
+	body, err := ps.parseList(true)
+	if err != nil {
+		return nil, err
+	}
 
-	for i < len(tokens) {
-		token := p.expandVariables(tokens[i], env)
+	cmd := domain.NewCommand(name)
+	cmd.Function = domain.NewFunction(name, body)
+	return cmd, nil
+}
 
-		switch token {
-		case ">", ">>", "<":
-			if i+1 >= len(tokens) {
-				return nil, &ParseError{"missing filename for redirection"}
+// parseSimpleCommand разбирает простую команду с аргументами и редиректами
+func (ps *commandParser) parseSimpleCommand() (*domain.Command, error) {
+	var cmd *domain.Command
+
+	for ps.peek().kind == tokenWord {
+		if ps.pos == ps.aliasNext {
+			if err := ps.expandAliases(); err != nil {
+				return nil, err
 			}
+			if ps.peek().kind != tokenWord {
+				break
+			}
+		}
 
-			filename := p.expandVariables(tokens[i+1], env)
-			switch token {
+		word := ps.advance().value
+
+		switch word {
+		case ">", ">>", "<":
+			if ps.peek().kind != tokenWord {
+				return nil, &ParseError{Message: "missing filename for redirection"}
+			}
+			filename := ps.advance().value
+			if cmd == nil {
+				cmd = domain.NewCommand("")
+			}
+			switch word {
 			case ">":
 				cmd.SetOutput(filename, false)
 			case ">>":
@@ -142,79 +308,66 @@ func (p *CommandParserAdapter) parseCommand(cmdStr string, env map[string]string
 			case "<":
 				cmd.SetInput(filename)
 			}
-			i += 2   // Пропускаем и оператор и имя файла
-			continue // Не добавляем оператор как аргумент
-
-		case "&":
-			cmd.Background = true
-			i++
-			continue
 
 		default:
-			cmd.AddArg(token)
-			i++
-		}
-	}
-
-	return cmd, nil
-}
-
-// tokenize разбивает строку на токены с учетом кавычек
-func (p *CommandParserAdapter) tokenize(input string) []string {
-	var tokens []string
-	var current strings.Builder
-	inQuotes := false
-	quoteChar := byte(' ')
-
-	for i := 0; i < len(input); i++ {
-		ch := input[i]
-
-		switch {
-		case ch == '"' || ch == '\'':
-			if !inQuotes {
-				inQuotes = true
-				quoteChar = ch
-			} else if ch == quoteChar {
-				inQuotes = false
-				if current.Len() > 0 {
-					tokens = append(tokens, current.String())
-					current.Reset()
+			if cmd == nil || cmd.Name == "" {
+				assignment, err := parseAssignmentWord(word)
+				if err != nil {
+					return nil, err
+				}
+				// Слова-присваивания перед именем команды
+				if assignment != nil {
+					if cmd == nil {
+						cmd = domain.NewCommand("")
+					}
+					cmd.AddAssignment(assignment)
+					// После присваиваний следующее слово тоже может быть псевдонимом
+					ps.aliasNext = ps.pos
+					continue
 				}
-			} else {
-				current.WriteByte(ch)
 			}
 
-		case ch == ' ' && !inQuotes:
-			if current.Len() > 0 {
-				tokens = append(tokens, current.String())
-				current.Reset()
+			if cmd == nil {
+				cmd = domain.NewCommand(word)
+			} else if cmd.Name == "" {
+				cmd.Name = word
+			} else {
+				cmd.AddArg(word)
 			}
-
-		default:
-			current.WriteByte(ch)
 		}
 	}
 
-	if current.Len() > 0 {
-		tokens = append(tokens, current.String())
+	if cmd == nil {
+		return nil, unexpectedToken(ps.peek())
 	}
+	return cmd, nil
+}
 
-	return tokens
+// isValidFunctionName проверяет имя функции
+func isValidFunctionName(name string) bool {
+	if name == "" || strings.ContainsAny(name, "'\"\\$`=<>{}") {
+		return false
+	}
+	return true
 }
 
-// expandVariables заменяет переменные окружения в строке
-func (p *CommandParserAdapter) expandVariables(input string, env map[string]string) string {
-	return os.Expand(input, func(key string) string {
-		if value, exists := env[key]; exists {
-			return value
-		}
-		return os.Getenv(key)
-	})
+// unexpectedToken формирует ошибку о неожиданной лексеме
+func unexpectedToken(tok token) *ParseError {
+	switch tok.kind {
+	case tokenEOF:
+		return &ParseError{Message: "syntax error: unexpected end of file", Incomplete: true}
+	case tokenNewline:
+		return &ParseError{Message: "syntax error near unexpected token `newline'"}
+	default:
+		return &ParseError{Message: "syntax error near unexpected token `" + tok.value + "'"}
+	}
 }
 
 // ParseError представляет ошибку парсинга
 type ParseError struct {
 	Message string
+	// Incomplete означает, что ввод оборвался и может быть продолжен
+	Incomplete bool
 }
 
 func (e *ParseError) Error() string {
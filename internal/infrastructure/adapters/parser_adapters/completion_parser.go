@@ -0,0 +1,186 @@
+package parser_adapters
+
+import (
+	"minishell/internal/domain"
+	"strings"
+)
+
+// completionSeparators - операторы, после которых начинается новая команда
+var completionSeparators = []string{"&&", "||", ";", "&", "|", "("}
+
+// ParseCompletionLine разбирает строку до и после курсора для дополнения:
+// находит дополняемое слово, снимает с его начала кавычки и определяет,
+// стоит ли оно на месте имени команды. В отличие от Parse, незакрытые
+// кавычки и незавершенные конструкции не считаются ошибкой.
+func (p *CommandParserAdapter) ParseCompletionLine(line string, cursor int) *domain.CompletionLine {
+	sc := &completionScanner{line: line, cursor: cursor, commandPosition: true}
+	sc.scan()
+	sc.result.Words = sc.words
+	return sc.result
+}
+
+// completionScanner - разбор строки на слова для дополнения
+type completionScanner struct {
+	line   string
+	cursor int
+
+	words []string
+	// commandPosition - следующее слово будет именем команды
+	commandPosition bool
+	// redirection - предыдущая лексема - оператор перенаправления
+	redirection bool
+	// command - имя текущей команды после снятия кавычек
+	command string
+
+	result *domain.CompletionLine
+}
+
+// scan проходит по строке; слова команды под курсором собираются,
+// пока после курсора не встретится разделитель команд
+func (sc *completionScanner) scan() {
+	pos := 0
+	for pos < len(sc.line) {
+		ch := sc.line[pos]
+
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n':
+			pos++
+			continue
+		case strings.IndexByte(";&|()<>", ch) >= 0:
+			if sc.result == nil && pos >= sc.cursor {
+				sc.setResult(sc.cursor, sc.cursor, "", 0, 0)
+			}
+			op := sc.operator(pos)
+			if sc.result != nil && sc.isSeparator(op) {
+				return
+			}
+			sc.applyOperator(op)
+			pos += len(op)
+			continue
+		}
+
+		if sc.result == nil && pos > sc.cursor {
+			// Курсор стоит между словами
+			sc.setResult(sc.cursor, sc.cursor, "", 0, 0)
+		}
+		pos = sc.word(pos)
+	}
+
+	if sc.result == nil {
+		sc.setResult(sc.cursor, sc.cursor, "", 0, 0)
+	}
+}
+
+// word читает слово с позиции start и возвращает позицию после него;
+// если курсор внутри слова или на его конце, слово становится дополняемым
+func (sc *completionScanner) word(start int) int {
+	var unquoted strings.Builder
+	var quote byte
+	quoteStart := 0
+	pos := start
+
+	for pos < len(sc.line) {
+		if sc.result == nil && pos == sc.cursor {
+			sc.setResult(start, pos, unquoted.String(), quote, quoteStart)
+		}
+
+		ch := sc.line[pos]
+		if quote == 0 && (strings.IndexByte(" \t\n;&|()<>", ch) >= 0) {
+			break
+		}
+
+		switch {
+		case quote != 0 && ch == quote:
+			quote = 0
+		case quote == '\'':
+			unquoted.WriteByte(ch)
+		case ch == '\\' && pos+1 < len(sc.line) && (quote == 0 || strings.IndexByte("\"\\$`", sc.line[pos+1]) >= 0):
+			pos++
+			unquoted.WriteByte(sc.line[pos])
+		case quote == 0 && (ch == '\'' || ch == '"'):
+			quote = ch
+			quoteStart = pos - start
+		default:
+			unquoted.WriteByte(ch)
+		}
+		pos++
+	}
+
+	if sc.result == nil && pos == sc.cursor {
+		sc.setResult(start, pos, unquoted.String(), quote, quoteStart)
+	}
+
+	word := sc.line[start:pos]
+	sc.words = append(sc.words, word)
+	sc.advanceCommandPosition(word, unquoted.String())
+	return pos
+}
+
+// setResult запоминает дополняемое слово [start, end) и его состояние
+func (sc *completionScanner) setResult(start, end int, prefix string, quote byte, quoteStart int) {
+	sc.result = &domain.CompletionLine{
+		Current:         len(sc.words),
+		Start:           start,
+		Raw:             sc.line[start:end],
+		Prefix:          prefix,
+		Quote:           quote,
+		QuoteStart:      quoteStart,
+		CommandPosition: sc.commandPosition && !sc.redirection,
+		Command:         sc.command,
+	}
+	if start == end {
+		// Новое пустое слово под курсором тоже входит в список слов
+		sc.words = append(sc.words, "")
+	}
+}
+
+// advanceCommandPosition обновляет признак места имени команды после слова
+// и запоминает имя команды; присваивания перед командой и { не меняют его
+func (sc *completionScanner) advanceCommandPosition(word, unquoted string) {
+	if sc.redirection {
+		sc.redirection = false
+		return
+	}
+	if !sc.commandPosition || word == "{" || isAssignmentWord(word) {
+		return
+	}
+	sc.commandPosition = false
+	sc.command = unquoted
+}
+
+// operator возвращает оператор, начинающийся с позиции pos
+func (sc *completionScanner) operator(pos int) string {
+	for _, op := range []string{"&&", "||", ">>", ">&", "<&", "&>"} {
+		if strings.HasPrefix(sc.line[pos:], op) {
+			return op
+		}
+	}
+	return sc.line[pos : pos+1]
+}
+
+// isSeparator проверяет, начинает ли оператор новую команду
+func (sc *completionScanner) isSeparator(op string) bool {
+	for _, sep := range completionSeparators {
+		if op == sep {
+			return true
+		}
+	}
+	return false
+}
+
+// applyOperator учитывает оператор: после разделителя начинается новая
+// команда, после перенаправления идет имя файла
+func (sc *completionScanner) applyOperator(op string) {
+	switch {
+	case sc.isSeparator(op):
+		sc.words = nil
+		sc.command = ""
+		sc.commandPosition = true
+		sc.redirection = false
+	case op == ")":
+		sc.commandPosition = false
+	default:
+		sc.words = append(sc.words, op)
+		sc.redirection = true
+	}
+}
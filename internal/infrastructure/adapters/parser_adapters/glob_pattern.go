@@ -0,0 +1,351 @@
+package parser_adapters
+
+import (
+	"minishell/internal/domain"
+	"minishell/pkg/constants"
+	"strings"
+	"unicode"
+)
+
+// globNodeKind - тип элемента шаблона
+type globNodeKind int
+
+const (
+	globLiteral globNodeKind = iota
+	globAnyChar
+	globStar
+	globClass
+	globExtGroup
+)
+
+// globNode - элемент разобранного шаблона
+type globNode struct {
+	kind    globNodeKind
+	char    rune
+	class   *globCharClass
+	extKind rune
+	alts    [][]globNode
+}
+
+// globCharClass - класс символов [...]
+type globCharClass struct {
+	negate bool
+	ranges [][2]rune
+	named  []string
+}
+
+// globPattern - скомпилированный шаблон для сопоставления строк
+type globPattern struct {
+	nodes      []globNode
+	ignoreCase bool
+}
+
+// compileGlob разбирает шаблон. Символы, экранированные обратной косой чертой,
+// считаются литералами; при extglob распознаются ?(..) *(..) +(..) @(..) !(..)
+func compileGlob(pattern string, extglob, ignoreCase bool) *globPattern {
+	nodes, _ := parseGlobNodes([]rune(pattern), 0, extglob, false)
+	return &globPattern{nodes: nodes, ignoreCase: ignoreCase}
+}
+
+// MatchPattern сопоставляет строку с шаблоном целиком без правил имен
+// файлов: * и ? совпадают и с /, и с точкой в начале
+func (e *WordExpanderAdapter) MatchPattern(pattern, s string, ctx *domain.ExecutionContext) bool {
+	return compileGlob(pattern, ctx.IsOptionSet(constants.OptExtglob), false).Match(s)
+}
+
+// hasGlobMeta проверяет, содержит ли шаблон неэкранированные спецсимволы
+func hasGlobMeta(pattern string, extglob bool) bool {
+	for _, node := range compileGlob(pattern, extglob, false).nodes {
+		if node.kind != globLiteral {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeGlob экранирует спецсимволы шаблона
+func escapeGlob(s string) string {
+	var result strings.Builder
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(`*?[]\!@+()|`, s[i]) >= 0 {
+			result.WriteByte('\\')
+		}
+		result.WriteByte(s[i])
+	}
+	return result.String()
+}
+
+// unescapeGlob снимает экранирование с шаблона без спецсимволов
+func unescapeGlob(pattern string) string {
+	var result strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == '\\' && i+1 < len(pattern) {
+			i++
+		}
+		result.WriteByte(pattern[i])
+	}
+	return result.String()
+}
+
+// parseGlobNodes разбирает шаблон до конца строки или до | и ) внутри группы
+func parseGlobNodes(p []rune, i int, extglob, inGroup bool) ([]globNode, int) {
+	var nodes []globNode
+
+	for i < len(p) {
+		ch := p[i]
+
+		switch {
+		case inGroup && (ch == '|' || ch == ')'):
+			return nodes, i
+
+		case ch == '\\' && i+1 < len(p):
+			nodes = append(nodes, globNode{kind: globLiteral, char: p[i+1]})
+			i += 2
+
+		case extglob && strings.ContainsRune("?*+@!", ch) && i+1 < len(p) && p[i+1] == '(':
+			if group, next, ok := parseGlobGroup(p, i, extglob); ok {
+				nodes = append(nodes, group)
+				i = next
+				continue
+			}
+			nodes = append(nodes, globNode{kind: globLiteral, char: ch})
+			i++
+
+		case ch == '*':
+			// Несколько звездочек подряд эквивалентны одной
+			if len(nodes) == 0 || nodes[len(nodes)-1].kind != globStar {
+				nodes = append(nodes, globNode{kind: globStar})
+			}
+			i++
+
+		case ch == '?':
+			nodes = append(nodes, globNode{kind: globAnyChar})
+			i++
+
+		case ch == '[':
+			if class, next, ok := parseGlobClass(p, i); ok {
+				nodes = append(nodes, globNode{kind: globClass, class: class})
+				i = next
+				continue
+			}
+			nodes = append(nodes, globNode{kind: globLiteral, char: ch})
+			i++
+
+		default:
+			nodes = append(nodes, globNode{kind: globLiteral, char: ch})
+			i++
+		}
+	}
+
+	return nodes, i
+}
+
+// parseGlobGroup разбирает группу extglob, начинающуюся в позиции i
+func parseGlobGroup(p []rune, i int, extglob bool) (globNode, int, bool) {
+	group := globNode{kind: globExtGroup, extKind: p[i]}
+	i += 2
+
+	for {
+		alt, next := parseGlobNodes(p, i, extglob, true)
+		if next >= len(p) {
+			return globNode{}, 0, false
+		}
+		group.alts = append(group.alts, alt)
+		i = next + 1
+		if p[next] == ')' {
+			return group, i, true
+		}
+	}
+}
+
+// parseGlobClass разбирает класс символов, начинающийся в позиции i
+func parseGlobClass(p []rune, i int) (*globCharClass, int, bool) {
+	class := &globCharClass{}
+	i++
+
+	if i < len(p) && (p[i] == '!' || p[i] == '^') {
+		class.negate = true
+		i++
+	}
+
+	first := true
+	for i < len(p) {
+		ch := p[i]
+
+		switch {
+		case ch == ']' && !first:
+			return class, i + 1, true
+
+		case ch == '[' && i+1 < len(p) && p[i+1] == ':':
+			end := strings.Index(string(p[i+2:]), ":]")
+			if end < 0 {
+				class.ranges = append(class.ranges, [2]rune{ch, ch})
+				i++
+				break
+			}
+			name := string(p[i+2:])[:end]
+			class.named = append(class.named, name)
+			i += 2 + len([]rune(name)) + 2
+
+		default:
+			if ch == '\\' && i+1 < len(p) {
+				i++
+				ch = p[i]
+			}
+			if i+2 < len(p) && p[i+1] == '-' && p[i+2] != ']' {
+				class.ranges = append(class.ranges, [2]rune{ch, p[i+2]})
+				i += 3
+			} else {
+				class.ranges = append(class.ranges, [2]rune{ch, ch})
+				i++
+			}
+		}
+		first = false
+	}
+
+	return nil, 0, false
+}
+
+// Match проверяет, соответствует ли строка шаблону целиком
+func (g *globPattern) Match(s string) bool {
+	return g.matchNodes(g.nodes, []rune(s))
+}
+
+// matchNodes сопоставляет строку с последовательностью элементов шаблона
+func (g *globPattern) matchNodes(nodes []globNode, s []rune) bool {
+	if len(nodes) == 0 {
+		return len(s) == 0
+	}
+
+	node := nodes[0]
+	switch node.kind {
+	case globLiteral:
+		return len(s) > 0 && g.equalRunes(node.char, s[0]) && g.matchNodes(nodes[1:], s[1:])
+
+	case globAnyChar:
+		return len(s) > 0 && g.matchNodes(nodes[1:], s[1:])
+
+	case globClass:
+		return len(s) > 0 && g.matchClass(node.class, s[0]) && g.matchNodes(nodes[1:], s[1:])
+
+	case globStar:
+		for i := 0; i <= len(s); i++ {
+			if g.matchNodes(nodes[1:], s[i:]) {
+				return true
+			}
+		}
+		return false
+
+	case globExtGroup:
+		for i := 0; i <= len(s); i++ {
+			if g.matchGroup(node, s[:i]) && g.matchNodes(nodes[1:], s[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return false
+}
+
+// matchGroup проверяет соответствие строки группе extglob
+func (g *globPattern) matchGroup(node globNode, s []rune) bool {
+	matchAny := func(part []rune) bool {
+		for _, alt := range node.alts {
+			if g.matchNodes(alt, part) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// matchRepeated - одно или более повторений альтернатив
+	var matchRepeated func(part []rune) bool
+	matchRepeated = func(part []rune) bool {
+		if matchAny(part) {
+			return true
+		}
+		for i := 1; i < len(part); i++ {
+			if matchAny(part[:i]) && matchRepeated(part[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch node.extKind {
+	case '?':
+		return len(s) == 0 || matchAny(s)
+	case '*':
+		return len(s) == 0 || matchRepeated(s)
+	case '+':
+		return matchRepeated(s)
+	case '!':
+		return !matchAny(s)
+	default:
+		return matchAny(s)
+	}
+}
+
+// matchClass проверяет принадлежность символа классу
+func (g *globPattern) matchClass(class *globCharClass, ch rune) bool {
+	matched := false
+
+	for _, r := range class.ranges {
+		if (ch >= r[0] && ch <= r[1]) ||
+			(g.ignoreCase && (unicode.ToLower(ch) >= unicode.ToLower(r[0]) && unicode.ToLower(ch) <= unicode.ToLower(r[1]) ||
+				unicode.ToUpper(ch) >= unicode.ToUpper(r[0]) && unicode.ToUpper(ch) <= unicode.ToUpper(r[1]))) {
+			matched = true
+			break
+		}
+	}
+
+	for _, name := range class.named {
+		if matchNamedClass(name, ch) {
+			matched = true
+			break
+		}
+	}
+
+	return matched != class.negate
+}
+
+// matchNamedClass проверяет символ на принадлежность POSIX-классу [:name:]
+func matchNamedClass(name string, ch rune) bool {
+	switch name {
+	case "alpha":
+		return unicode.IsLetter(ch)
+	case "digit":
+		return ch >= '0' && ch <= '9'
+	case "alnum":
+		return unicode.IsLetter(ch) || unicode.IsDigit(ch)
+	case "upper":
+		return unicode.IsUpper(ch)
+	case "lower":
+		return unicode.IsLower(ch)
+	case "space":
+		return unicode.IsSpace(ch)
+	case "blank":
+		return ch == ' ' || ch == '\t'
+	case "punct":
+		return unicode.IsPunct(ch) || unicode.IsSymbol(ch)
+	case "xdigit":
+		return strings.ContainsRune("0123456789abcdefABCDEF", ch)
+	case "cntrl":
+		return unicode.IsControl(ch)
+	case "print":
+		return unicode.IsPrint(ch)
+	case "graph":
+		return unicode.IsPrint(ch) && ch != ' '
+	default:
+		return false
+	}
+}
+
+// equalRunes сравнивает символы с учетом режима без учета регистра
+func (g *globPattern) equalRunes(a, b rune) bool {
+	if g.ignoreCase {
+		return unicode.ToLower(a) == unicode.ToLower(b)
+	}
+	return a == b
+}
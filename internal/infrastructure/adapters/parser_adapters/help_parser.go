@@ -0,0 +1,303 @@
+package parser_adapters
+
+import (
+	"minishell/internal/domain"
+	"strings"
+)
+
+// helpOptionIndent - наибольший отступ строки, описывающей параметр
+// в выводе --help
+const helpOptionIndent = 12
+
+// ParseHelpOptions находит параметры в выводе --help: строки, которые
+// начинаются с -, делятся на список имен и описание двумя пробелами;
+// описание без текста на той же строке берется из следующей строки
+func (p *CommandParserAdapter) ParseHelpOptions(help string) []domain.CommandOption {
+	options := &optionList{seen: make(map[string]bool)}
+	lines := strings.Split(help, "\n")
+
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		indent := len(line) - len(trimmed)
+		if indent > helpOptionIndent || !isOptionName(trimmed) {
+			continue
+		}
+
+		tag, description := splitHelpLine(strings.TrimRight(trimmed, " \t\r"))
+		if description == "" && i+1 < len(lines) {
+			next := strings.TrimSpace(lines[i+1])
+			nextIndent := len(lines[i+1]) - len(strings.TrimLeft(lines[i+1], " \t"))
+			if nextIndent > indent && !strings.HasPrefix(next, "-") {
+				description = next
+			}
+		}
+		options.add(tag, description)
+	}
+	return options.options
+}
+
+// ParseManPageOptions находит параметры в исходном тексте man-страницы:
+// абзацы .TP и .IP, а также .It Fl в формате mdoc, метка которых
+// начинается с -. Описанием служит первое предложение абзаца.
+func (p *CommandParserAdapter) ParseManPageOptions(page string) []domain.CommandOption {
+	options := &optionList{seen: make(map[string]bool)}
+	var tag string
+	var description []string
+	expectTag := false
+
+	flush := func() {
+		if tag != "" {
+			options.add(tag, strings.Join(description, " "))
+		}
+		tag, description = "", nil
+	}
+	text := func(line string) {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case expectTag:
+			expectTag = false
+			if isOptionName(line) {
+				tag = line
+			}
+		case tag != "":
+			description = append(description, line)
+		}
+	}
+
+	for _, line := range strings.Split(page, "\n") {
+		if !strings.HasPrefix(line, ".") && !strings.HasPrefix(line, "'") {
+			text(cleanRoff(line))
+			continue
+		}
+
+		macro, args := splitMacro(line)
+		switch macro {
+		case "TP", "TQ":
+			flush()
+			expectTag = true
+		case "IP":
+			flush()
+			expectTag = false
+			if len(args) > 0 && isOptionName(cleanRoff(args[0])) {
+				tag = cleanRoff(args[0])
+			}
+		case "It":
+			flush()
+			expectTag = false
+			tag = mdocOptionTag(args)
+		case "PP", "P", "LP", "HP", "SH", "SS", "RS", "RE", "Sh", "Ss", "Bl", "El", "Pp":
+			flush()
+			expectTag = false
+		case "B", "I", "SM", "SB":
+			text(cleanRoff(strings.Join(args, " ")))
+		case "BR", "BI", "IB", "IR", "RB", "RI":
+			text(cleanRoff(strings.Join(args, "")))
+		}
+	}
+	flush()
+	return options.options
+}
+
+// optionList - найденные параметры без повторов имен
+type optionList struct {
+	options []domain.CommandOption
+	seen    map[string]bool
+}
+
+// add добавляет параметры из списка имен вида "-a, --all" или
+// "-w COLS, --width=COLS" с общим описанием
+func (l *optionList) add(tag, description string) {
+	description = summarizeDescription(description)
+
+	for _, part := range strings.Split(tag, ",") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 || !isOptionName(fields[0]) {
+			continue
+		}
+		field := fields[0]
+
+		name := field
+		if end := strings.IndexAny(field, "=[<"); end > 0 {
+			name = field[:end]
+			if field[end] == '=' && strings.HasPrefix(name, "--") {
+				// Значение длинного параметра вводится сразу после =
+				name += "="
+			}
+		}
+		if l.seen[name] {
+			continue
+		}
+		l.seen[name] = true
+		l.options = append(l.options, domain.CommandOption{Name: name, Description: description})
+	}
+}
+
+// isOptionName проверяет, начинается ли текст с имени параметра: -x или --name
+func isOptionName(text string) bool {
+	name := strings.TrimPrefix(text, "-")
+	if len(name) == len(text) {
+		return false
+	}
+	name = strings.TrimPrefix(name, "-")
+	return name != "" && isOptionChar(name[0])
+}
+
+// isOptionChar проверяет, может ли символ начинать имя параметра
+func isOptionChar(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9'
+}
+
+// splitHelpLine делит строку --help на список имен и описание по первому
+// промежутку из двух и более пробелов или табуляции
+func splitHelpLine(line string) (string, string) {
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\t' || (line[i] == ' ' && i+1 < len(line) && line[i+1] == ' ') {
+			return line[:i], strings.TrimSpace(line[i:])
+		}
+	}
+	return line, ""
+}
+
+// summarizeDescription сокращает описание до первого предложения
+// и убирает лишние пробелы
+func summarizeDescription(description string) string {
+	description = strings.Join(strings.Fields(description), " ")
+	for i := 1; i+2 < len(description); i++ {
+		next := description[i+2]
+		if description[i] == ';' || (description[i] == '.' && description[i-1] != ' ' && next >= 'A' && next <= 'Z') {
+			if description[i+1] == ' ' {
+				description = description[:i]
+				break
+			}
+		}
+	}
+	// Точка в конце убирается, если она завершает предложение, а не
+	// обозначает файл или каталог
+	if n := len(description); n > 1 && description[n-1] == '.' && isOptionChar(description[n-2]) {
+		description = description[:n-1]
+	}
+	return description
+}
+
+// splitMacro делит строку макроса roff на имя и аргументы с учетом
+// двойных кавычек
+func splitMacro(line string) (string, []string) {
+	line = strings.TrimLeft(line[1:], " \t")
+	var args []string
+	var current strings.Builder
+	inQuotes, started := false, false
+
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		switch {
+		case ch == '"' && inQuotes && i+1 < len(line) && line[i+1] == '"':
+			current.WriteByte('"')
+			i++
+		case ch == '"':
+			inQuotes = !inQuotes
+			started = true
+		case (ch == ' ' || ch == '\t') && !inQuotes:
+			if started {
+				args = append(args, current.String())
+				current.Reset()
+				started = false
+			}
+		case ch == '\\' && i+1 < len(line) && line[i+1] == '"' && !inQuotes:
+			// Комментарий до конца строки
+			i = len(line)
+		default:
+			current.WriteByte(ch)
+			started = true
+		}
+	}
+	if started {
+		args = append(args, current.String())
+	}
+
+	if len(args) == 0 {
+		return "", nil
+	}
+	return args[0], args[1:]
+}
+
+// mdocOptionTag возвращает параметр из аргументов .It вида "Fl a Ar file"
+func mdocOptionTag(args []string) string {
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == "Fl" {
+			return "-" + cleanRoff(args[i+1])
+		}
+	}
+	return ""
+}
+
+// roffGlyphs - замены именованных символов roff
+var roffGlyphs = map[string]string{
+	"aq": "'", "dq": `"`, "lq": `"`, "rq": `"`, "oq": "'", "cq": "'",
+	"em": "-", "en": "-", "hy": "-", "mi": "-", "bu": "*", "ti": "~",
+	"ha": "^", "rs": `\`, "ga": "`", "co": "(C)",
+}
+
+// cleanRoff убирает из текста roff смену шрифта и управляющие
+// последовательности, заменяя специальные символы обычными
+func cleanRoff(text string) string {
+	var out strings.Builder
+	for i := 0; i < len(text); i++ {
+		ch := text[i]
+		if ch != '\\' || i+1 >= len(text) {
+			out.WriteByte(ch)
+			continue
+		}
+
+		i++
+		switch text[i] {
+		case 'f', '*':
+			// Шрифт \fB, \f(CW, \f[B] и строки \*(lq, \*[name]
+			glyph, next := roffName(text, i+1)
+			if text[i] == '*' {
+				out.WriteString(roffGlyphs[glyph])
+			}
+			i = next - 1
+		case '(', '[':
+			glyph, next := roffName(text, i)
+			out.WriteString(roffGlyphs[glyph])
+			i = next - 1
+		case 's':
+			// Размер шрифта \s+2, \s0
+			for i+1 < len(text) && strings.IndexByte("+-0123456789", text[i+1]) >= 0 {
+				i++
+			}
+		case '-':
+			out.WriteByte('-')
+		case 'e', '\\':
+			out.WriteByte('\\')
+		case ' ', '~':
+			out.WriteByte(' ')
+		case '&', ',', '/', '|', '^', ':', 'c':
+		case '"':
+			return out.String()
+		default:
+			out.WriteByte(text[i])
+		}
+	}
+	return out.String()
+}
+
+// roffName читает имя после управляющего символа с позиции pos: один
+// символ, два символа после ( или имя в квадратных скобках; возвращает
+// имя и позицию после него
+func roffName(text string, pos int) (string, int) {
+	switch {
+	case pos >= len(text):
+		return "", pos
+	case text[pos] == '(':
+		end := min(pos+3, len(text))
+		return text[pos+1 : end], end
+	case text[pos] == '[':
+		if end := strings.IndexByte(text[pos:], ']'); end >= 0 {
+			return text[pos+1 : pos+end], pos + end + 1
+		}
+		return text[pos+1:], len(text)
+	}
+	return text[pos : pos+1], pos + 1
+}
@@ -0,0 +1,242 @@
+package parser_adapters
+
+import (
+	"minishell/internal/domain"
+	"strings"
+)
+
+// highlightKeywords - зарезервированные слова на месте команды
+var highlightKeywords = map[string]bool{"function": true, "{": true, "}": true}
+
+// ParseHighlightLine разбивает строку на фрагменты для подсветки синтаксиса:
+// имена команд, ключевые слова, строки в кавычках, подстановки переменных,
+// операторы, перенаправления и комментарии. Незакрытая кавычка отмечается
+// как ошибка до конца строки. Как и ParseCompletionLine, разбор не требует
+// завершенной команды.
+func (p *CommandParserAdapter) ParseHighlightLine(line string) []domain.HighlightSpan {
+	sc := &highlightScanner{line: line, commandPosition: true}
+	sc.scan()
+	return sc.spans
+}
+
+// highlightScanner - разбор строки для подсветки синтаксиса
+type highlightScanner struct {
+	line  string
+	spans []domain.HighlightSpan
+	// commandPosition - следующее слово будет именем команды
+	commandPosition bool
+	// redirection - следующее слово - файл перенаправления
+	redirection bool
+	// definition - следующее слово - имя функции после function
+	definition bool
+}
+
+// scan проходит по строке и собирает фрагменты
+func (sc *highlightScanner) scan() {
+	pos := 0
+	for pos < len(sc.line) {
+		ch := sc.line[pos]
+
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n':
+			pos++
+		case ch == '#':
+			sc.add(pos, len(sc.line), domain.HighlightComment)
+			return
+		case sc.redirectionLength(pos) > 0:
+			end := pos + sc.redirectionLength(pos)
+			sc.add(pos, end, domain.HighlightRedirection)
+			sc.redirection = true
+			pos = end
+		case strings.IndexByte(";&|()", ch) >= 0:
+			pos = sc.operator(pos)
+		default:
+			pos = sc.word(pos)
+		}
+	}
+}
+
+// add добавляет фрагмент строки
+func (sc *highlightScanner) add(start, end int, kind domain.HighlightKind) {
+	sc.spans = append(sc.spans, domain.HighlightSpan{Start: start, End: end, Kind: kind})
+}
+
+// redirectionLength возвращает длину оператора перенаправления с позиции pos
+// вместе с номером дескриптора перед ним или 0
+func (sc *highlightScanner) redirectionLength(pos int) int {
+	end := pos
+	for end < len(sc.line) && sc.line[end] >= '0' && sc.line[end] <= '9' {
+		end++
+	}
+	rest := sc.line[end:]
+	if end > pos && !strings.HasPrefix(rest, "<") && !strings.HasPrefix(rest, ">") {
+		return 0
+	}
+
+	for _, op := range []string{"&>>", "<<<", "<<-", "&>", ">>", ">&", "<&", "<<", "<>", ">|", ">", "<"} {
+		if strings.HasPrefix(rest, op) {
+			return end - pos + len(op)
+		}
+	}
+	return 0
+}
+
+// operator отмечает оператор с позиции pos и возвращает позицию после него;
+// после разделителя начинается новая команда
+func (sc *highlightScanner) operator(pos int) int {
+	op := sc.line[pos : pos+1]
+	for _, candidate := range []string{"&&", "||", ";;"} {
+		if strings.HasPrefix(sc.line[pos:], candidate) {
+			op = candidate
+		}
+	}
+	end := pos + len(op)
+
+	switch {
+	case op == "(" && strings.HasPrefix(strings.TrimLeft(sc.line[end:], " \t"), ")"):
+		// () определения функции: дальше идет тело
+		end = strings.IndexByte(sc.line[end:], ')') + end + 1
+		sc.commandPosition = true
+	case op == ")":
+		sc.commandPosition = false
+	default:
+		sc.commandPosition = true
+	}
+	sc.redirection = false
+	sc.add(pos, end, domain.HighlightOperator)
+	return end
+}
+
+// word читает слово с позиции start, отмечает в нем строки в кавычках
+// и подстановки и возвращает позицию после слова
+func (sc *highlightScanner) word(start int) int {
+	var unquoted strings.Builder
+	var quote byte
+	quoteStart, quoteSpan := 0, 0
+	// plain - имя команды известно без раскрытия подстановок
+	plain := true
+	pos := start
+
+	for pos < len(sc.line) {
+		ch := sc.line[pos]
+		if quote == 0 && strings.IndexByte(" \t\n;&|()<>", ch) >= 0 {
+			if ch != '(' || !isAssignmentWord(sc.line[start:pos]) {
+				break
+			}
+			// Присваивание массива name=(...)
+			end := strings.IndexByte(sc.line[pos:], ')')
+			if end < 0 {
+				end = len(sc.line) - pos - 1
+			}
+			pos += end + 1
+			continue
+		}
+
+		switch {
+		case quote == '\'' && ch == '\'':
+			quote = 0
+			sc.spans[quoteSpan].End = pos + 1
+		case quote == '\'':
+			unquoted.WriteByte(ch)
+		case ch == '\\' && pos+1 < len(sc.line):
+			pos++
+			unquoted.WriteByte(sc.line[pos])
+		case ch == '$':
+			if n := dollarLength(sc.line[pos:]); n > 1 {
+				sc.add(pos, pos+n, domain.HighlightVariable)
+				plain = false
+				pos += n
+				continue
+			}
+			unquoted.WriteByte(ch)
+		case ch == '`':
+			plain = false
+		case quote == '"' && ch == '"':
+			quote = 0
+			sc.spans[quoteSpan].End = pos + 1
+		case quote == 0 && (ch == '\'' || ch == '"'):
+			quote, quoteStart, quoteSpan = ch, pos, len(sc.spans)
+			sc.add(pos, len(sc.line), domain.HighlightString)
+		case quote == 0 && strings.IndexByte("*?[~", ch) >= 0:
+			plain = false
+			unquoted.WriteByte(ch)
+		default:
+			unquoted.WriteByte(ch)
+		}
+		pos++
+	}
+
+	if quote != 0 {
+		sc.add(quoteStart, len(sc.line), domain.HighlightError)
+	}
+	sc.classifyWord(start, pos, unquoted.String(), plain && quote == 0)
+	return pos
+}
+
+// classifyWord отмечает слово по его месту в команде: имя команды,
+// ключевое слово или присваивание перед командой
+func (sc *highlightScanner) classifyWord(start, end int, unquoted string, plain bool) {
+	word := sc.line[start:end]
+
+	switch {
+	case sc.redirection:
+		sc.redirection = false
+	case sc.definition:
+		// После имени функции идет ее тело
+		sc.definition = false
+		sc.commandPosition = true
+	case !sc.commandPosition:
+	case isAssignmentWord(word):
+		eq := strings.IndexByte(word, '=')
+		sc.add(start, start+eq, domain.HighlightVariable)
+	case highlightKeywords[word]:
+		sc.add(start, end, domain.HighlightKeyword)
+		sc.definition = word == "function"
+	default:
+		sc.commandPosition = false
+		if plain && !sc.isFunctionDefinition(end) {
+			sc.spans = append(sc.spans, domain.HighlightSpan{
+				Start: start,
+				End:   end,
+				Kind:  domain.HighlightCommand,
+				Text:  unquoted,
+			})
+		}
+	}
+}
+
+// isFunctionDefinition проверяет, идут ли после слова скобки () определения функции
+func (sc *highlightScanner) isFunctionDefinition(end int) bool {
+	return strings.HasPrefix(strings.TrimLeft(sc.line[end:], " \t"), "(")
+}
+
+// dollarLength возвращает длину подстановки в начале s, начинающейся с $:
+// $name, ${...}, $((...)), $1 и специальные параметры; 1 - если за $
+// не следует подстановка
+func dollarLength(s string) int {
+	if len(s) < 2 {
+		return 1
+	}
+
+	switch ch := s[1]; {
+	case ch == '{':
+		if end := strings.IndexByte(s, '}'); end >= 0 {
+			return end + 1
+		}
+		return len(s)
+	case strings.HasPrefix(s, "$(("):
+		if end := strings.Index(s, "))"); end >= 0 {
+			return end + 2
+		}
+		return len(s)
+	case isNameStart(ch):
+		n := 2
+		for n < len(s) && isNameChar(s[n]) {
+			n++
+		}
+		return n
+	case ch >= '0' && ch <= '9', strings.IndexByte("?$!#@*-", ch) >= 0:
+		return 2
+	}
+	return 1
+}
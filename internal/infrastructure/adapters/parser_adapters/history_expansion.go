@@ -0,0 +1,456 @@
+package parser_adapters
+
+import (
+	"fmt"
+	"minishell/internal/domain"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// historyWordDelimiters - символы, на которых заканчиваются строка поиска
+// !prefix и слово в записи истории
+const historyWordDelimiters = " \t\n;&|()<>"
+
+// ExpandHistory выполняет подстановку из истории до разбора строки: события
+// !!, !n, !-n, !prefix, !?substring?, сокращения !$, !^, !*, указатели слов
+// после двоеточия, модификаторы :h, :t, :r, :e, :s/old/new/ и быструю замену
+// ^old^new. Возвращает строку и признак того, что подстановка была.
+func (p *CommandParserAdapter) ExpandHistory(input string, history *domain.History) (string, bool, error) {
+	if !strings.ContainsAny(input, "!^") {
+		return input, false, nil
+	}
+	h := &historyExpander{input: input, history: history}
+	return h.expand()
+}
+
+// historyExpander - подстановка из истории в одной строке ввода
+type historyExpander struct {
+	input   string
+	pos     int
+	history *domain.History
+	// lastOld и lastNew - последняя замена :s для :& и пустого old
+	lastOld string
+	lastNew string
+}
+
+// expand проходит по строке и заменяет ссылки на историю; внутри
+// одинарных кавычек и после обратной косой черты ! не раскрывается
+func (h *historyExpander) expand() (string, bool, error) {
+	var out strings.Builder
+	expanded := false
+
+	if strings.HasPrefix(h.input, "^") {
+		text, err := h.quickSubstitution()
+		if err != nil {
+			return "", false, err
+		}
+		out.WriteString(text)
+		expanded = true
+	}
+
+	inSingle, inDouble := false, false
+	for h.pos < len(h.input) {
+		ch := h.input[h.pos]
+
+		switch {
+		case ch == '\\' && !inSingle && h.pos+1 < len(h.input):
+			out.WriteString(h.input[h.pos : h.pos+2])
+			h.pos += 2
+			continue
+		case ch == '\'' && !inDouble:
+			inSingle = !inSingle
+		case ch == '"' && !inSingle:
+			inDouble = !inDouble
+		case ch == '!' && !inSingle && h.startsEvent(inDouble):
+			text, err := h.event()
+			if err != nil {
+				return "", false, err
+			}
+			out.WriteString(text)
+			expanded = true
+			continue
+		}
+
+		out.WriteByte(ch)
+		h.pos++
+	}
+
+	return out.String(), expanded, nil
+}
+
+// startsEvent проверяет, начинает ли ! в текущей позиции ссылку на историю:
+// как в bash, ! перед пробелом, концом строки, = или ( остается символом,
+// как и в параметрах $! и ${!name}
+func (h *historyExpander) startsEvent(inDouble bool) bool {
+	if h.pos+1 >= len(h.input) {
+		return false
+	}
+	if strings.HasSuffix(h.input[:h.pos], "$") || strings.HasSuffix(h.input[:h.pos], "${") {
+		return false
+	}
+	next := h.input[h.pos+1]
+	if strings.IndexByte(" \t\n=(", next) >= 0 {
+		return false
+	}
+	return !(inDouble && next == '"')
+}
+
+// event разбирает ссылку, начинающуюся с !, и возвращает подставляемый текст
+func (h *historyExpander) event() (string, error) {
+	start := h.pos
+	h.pos++
+
+	line, err := h.eventLine()
+	if err != nil {
+		return "", err
+	}
+
+	text, err := h.wordDesignator(line)
+	if err == nil {
+		text, err = h.modifiers(text)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", h.input[start:h.pos], err)
+	}
+	return text, nil
+}
+
+// eventLine разбирает указатель события и возвращает запись истории
+func (h *historyExpander) eventLine() (string, error) {
+	start := h.pos - 1
+	ch := h.input[h.pos]
+
+	switch {
+	case ch == '!':
+		h.pos++
+		return h.relative(1, start)
+	case ch == '$' || ch == '^' || ch == '*' || ch == ':':
+		// !$, !^, !* и !:слово относятся к предыдущей команде
+		return h.relative(1, start)
+	case ch == '-' || isDigit(ch):
+		end := h.pos + 1
+		for end < len(h.input) && isDigit(h.input[end]) {
+			end++
+		}
+		n, err := strconv.Atoi(h.input[h.pos:end])
+		if err != nil {
+			return "", fmt.Errorf("%s: event not found", h.input[start:end])
+		}
+		h.pos = end
+		if n < 0 {
+			return h.relative(-n, start)
+		}
+		return h.numbered(n, start)
+	case ch == '?':
+		end := strings.IndexAny(h.input[h.pos+1:], "?\n")
+		search := h.input[h.pos+1:]
+		h.pos = len(h.input)
+		if end >= 0 {
+			search = search[:end]
+			h.pos = start + 2 + end
+			if h.input[h.pos] == '?' {
+				h.pos++
+			}
+		}
+		return h.search(search, true, start)
+	}
+
+	end := h.pos
+	for end < len(h.input) && strings.IndexByte(historyWordDelimiters+":'\"`", h.input[end]) < 0 {
+		end++
+	}
+	prefix := h.input[h.pos:end]
+	h.pos = end
+	return h.search(prefix, false, start)
+}
+
+// relative возвращает n-ю с конца запись истории
+func (h *historyExpander) relative(n, start int) (string, error) {
+	entries := h.history.Entries()
+	if n <= 0 || n > len(entries) {
+		return "", fmt.Errorf("%s: event not found", h.input[start:h.pos])
+	}
+	return entries[len(entries)-n], nil
+}
+
+// numbered возвращает запись истории с номером n
+func (h *historyExpander) numbered(n, start int) (string, error) {
+	index := n - h.history.Base()
+	entries := h.history.Entries()
+	if index < 0 || index >= len(entries) {
+		return "", fmt.Errorf("%s: event not found", h.input[start:h.pos])
+	}
+	return entries[index], nil
+}
+
+// search ищет последнюю запись, начинающуюся с text (или содержащую его)
+func (h *historyExpander) search(text string, contains bool, start int) (string, error) {
+	entries := h.history.Entries()
+	for i := len(entries) - 1; i >= 0 && text != ""; i-- {
+		if (contains && strings.Contains(entries[i], text)) || strings.HasPrefix(entries[i], text) {
+			return entries[i], nil
+		}
+	}
+	return "", fmt.Errorf("%s: event not found", h.input[start:h.pos])
+}
+
+// wordDesignator разбирает указатель слов :n, :^, :$, :x-y, :*, :x* (двоеточие
+// перед ^, $, * и - можно опустить) и возвращает выбранные слова записи line
+func (h *historyExpander) wordDesignator(line string) (string, error) {
+	if h.pos >= len(h.input) {
+		return line, nil
+	}
+
+	ch := h.input[h.pos]
+	switch {
+	case ch == ':' && h.pos+1 < len(h.input) && isWordDesignator(h.input[h.pos+1]):
+		h.pos++
+	case ch == '^' || ch == '$' || ch == '*' || ch == '-':
+	default:
+		return line, nil
+	}
+
+	words := splitHistoryWords(line)
+	last := len(words) - 1
+
+	first, ok := h.wordIndex(last)
+	switch {
+	case h.input[h.pos-1] == '*' && !ok:
+		// :* - все аргументы
+		first = 1
+		return joinHistoryWords(words, first, last)
+	case !ok && h.peek() != '-':
+		return "", fmt.Errorf("bad word specifier")
+	case !ok:
+		first = 0
+	}
+
+	switch h.peek() {
+	case '*':
+		h.pos++
+		return joinHistoryWords(words, first, last)
+	case '-':
+		h.pos++
+		end, ok := h.wordIndex(last)
+		if !ok {
+			// x- - до предпоследнего слова
+			end = last - 1
+		}
+		return joinHistoryWords(words, first, end)
+	}
+	return joinHistoryWords(words, first, first)
+}
+
+// wordIndex разбирает номер слова: число, ^ (первый аргумент), $ (последнее
+// слово) или * (все аргументы, ok == false)
+func (h *historyExpander) wordIndex(last int) (int, bool) {
+	switch h.peek() {
+	case '^':
+		h.pos++
+		return 1, true
+	case '$':
+		h.pos++
+		return last, true
+	case '*':
+		h.pos++
+		return 0, false
+	}
+
+	end := h.pos
+	for end < len(h.input) && isDigit(h.input[end]) {
+		end++
+	}
+	if end == h.pos {
+		return 0, false
+	}
+	n, _ := strconv.Atoi(h.input[h.pos:end])
+	h.pos = end
+	return n, true
+}
+
+// modifiers применяет модификаторы :h, :t, :r, :e, :s/old/new/, :gs/old/new/ и :&
+func (h *historyExpander) modifiers(text string) (string, error) {
+	for h.peek() == ':' && h.pos+1 < len(h.input) {
+		modifier := h.input[h.pos+1]
+		h.pos += 2
+
+		switch modifier {
+		case 'h':
+			if i := strings.LastIndexByte(text, '/'); i > 0 {
+				text = text[:i]
+			} else if i == 0 {
+				text = "/"
+			}
+		case 't':
+			text = text[strings.LastIndexByte(text, '/')+1:]
+		case 'r':
+			text = strings.TrimSuffix(text, path.Ext(text))
+		case 'e':
+			text = strings.TrimPrefix(path.Ext(text), ".")
+		case 's', '&':
+			h.pos--
+			result, err := h.substitute(text, false)
+			if err != nil {
+				return "", err
+			}
+			text = result
+		case 'g':
+			result, err := h.substitute(text, true)
+			if err != nil {
+				return "", err
+			}
+			text = result
+		default:
+			return "", fmt.Errorf("%c: unrecognized history modifier", modifier)
+		}
+	}
+	return text, nil
+}
+
+// substitute применяет :s/old/new/ или :& к тексту; в new символ &
+// заменяется на old, пустой old означает old предыдущей замены
+func (h *historyExpander) substitute(text string, global bool) (string, error) {
+	switch h.peek() {
+	case '&':
+		h.pos++
+	case 's':
+		h.pos++
+		if h.pos >= len(h.input) {
+			return "", fmt.Errorf("substitution failed")
+		}
+		delimiter := h.input[h.pos]
+		h.pos++
+		old := h.delimited(delimiter)
+		h.lastNew = h.delimited(delimiter)
+		if old != "" {
+			h.lastOld = old
+		}
+	default:
+		return "", fmt.Errorf("unrecognized history modifier")
+	}
+
+	if h.lastOld == "" || !strings.Contains(text, h.lastOld) {
+		return "", fmt.Errorf("substitution failed")
+	}
+	replacement := strings.ReplaceAll(h.lastNew, "&", h.lastOld)
+	if global {
+		return strings.ReplaceAll(text, h.lastOld, replacement), nil
+	}
+	return strings.Replace(text, h.lastOld, replacement, 1), nil
+}
+
+// delimited читает текст до разделителя или конца строки; \разделитель
+// вставляет сам разделитель
+func (h *historyExpander) delimited(delimiter byte) string {
+	var text strings.Builder
+	for h.pos < len(h.input) {
+		ch := h.input[h.pos]
+		h.pos++
+		switch {
+		case ch == delimiter:
+			return text.String()
+		case ch == '\\' && h.pos < len(h.input) && h.input[h.pos] == delimiter:
+			text.WriteByte(delimiter)
+			h.pos++
+		default:
+			text.WriteByte(ch)
+		}
+	}
+	return text.String()
+}
+
+// quickSubstitution выполняет ^old^new^ - замену в предыдущей команде;
+// продолжение строки после последнего ^ добавляется к результату
+func (h *historyExpander) quickSubstitution() (string, error) {
+	h.pos = 1
+	old := h.delimited('^')
+	replacement := h.delimited('^')
+
+	line, err := h.relative(1, 0)
+	if err != nil {
+		return "", err
+	}
+	if old == "" || !strings.Contains(line, old) {
+		return "", fmt.Errorf("^%s^%s: substitution failed", old, replacement)
+	}
+	h.lastOld, h.lastNew = old, replacement
+	return strings.Replace(line, old, strings.ReplaceAll(replacement, "&", old), 1), nil
+}
+
+// peek возвращает текущий символ или 0 в конце строки
+func (h *historyExpander) peek() byte {
+	if h.pos < len(h.input) {
+		return h.input[h.pos]
+	}
+	return 0
+}
+
+// isWordDesignator проверяет, начинает ли символ указатель слов после двоеточия
+func isWordDesignator(ch byte) bool {
+	return isDigit(ch) || strings.IndexByte("^$*-", ch) >= 0
+}
+
+// splitHistoryWords разбивает запись истории на слова: кавычки не разрывают
+// слово, операторы ; & | ( ) < > - отдельные слова
+func splitHistoryWords(line string) []string {
+	var words []string
+	var current strings.Builder
+	var quote byte
+
+	flush := func() {
+		if current.Len() > 0 {
+			words = append(words, current.String())
+			current.Reset()
+		}
+	}
+
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		switch {
+		case quote != 0:
+			current.WriteByte(ch)
+			if ch == quote {
+				quote = 0
+			} else if ch == '\\' && quote == '"' && i+1 < len(line) {
+				i++
+				current.WriteByte(line[i])
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+			current.WriteByte(ch)
+		case ch == '\\' && i+1 < len(line):
+			current.WriteByte(ch)
+			i++
+			current.WriteByte(line[i])
+		case ch == ' ' || ch == '\t' || ch == '\n':
+			flush()
+		case strings.IndexByte(";&|()<>", ch) >= 0:
+			flush()
+			j := i
+			for j < len(line) && strings.IndexByte(";&|<>", line[j]) >= 0 && j-i < 2 {
+				j++
+			}
+			if j == i {
+				j++
+			}
+			words = append(words, line[i:j])
+			i = j - 1
+		default:
+			current.WriteByte(ch)
+		}
+	}
+	flush()
+	return words
+}
+
+// joinHistoryWords объединяет слова с first по last включительно
+func joinHistoryWords(words []string, first, last int) (string, error) {
+	if first < 0 || last >= len(words) || first > last+1 {
+		return "", fmt.Errorf("bad word specifier")
+	}
+	if first > last {
+		return "", nil
+	}
+	return strings.Join(words[first:last+1], " "), nil
+}
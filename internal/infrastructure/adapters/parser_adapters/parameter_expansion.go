@@ -0,0 +1,484 @@
+package parser_adapters
+
+import (
+	"fmt"
+	"minishell/internal/domain"
+	"minishell/pkg/constants"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// findParameterEnd возвращает позицию скобки, закрывающей ${ в начале s,
+// с учетом кавычек и вложенных подстановок; -1, если скобка не найдена
+func findParameterEnd(s string) int {
+	depth := 0
+
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '\'':
+			// Одинарные кавычки внутри ${...} сохраняют свое значение
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return -1
+			}
+			i += end + 1
+		case '"':
+			i = skipDoubleQuoted(s, i)
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// parameterNameLength возвращает длину имени параметра в начале s
+func parameterNameLength(s string) int {
+	if s == "" {
+		return 0
+	}
+
+	switch {
+	case strings.IndexByte(specialParameters, s[0]) >= 0:
+		return 1
+	case isDigit(s[0]):
+		n := 1
+		for n < len(s) && isDigit(s[n]) {
+			n++
+		}
+		return n
+	case isNameStart(s[0]):
+		n := 1
+		for n < len(s) && isNameChar(s[n]) {
+			n++
+		}
+		return n
+	default:
+		return 0
+	}
+}
+
+// expandParameter раскрывает конструкцию ${body}
+func (e *WordExpanderAdapter) expandParameter(body string, ctx *domain.ExecutionContext) (string, error) {
+	badSubstitution := fmt.Errorf("${%s}: bad substitution", body)
+
+	// ${#name} - длина значения, ${#a[@]} - количество элементов массива
+	if strings.HasPrefix(body, "#") && len(body) > 1 {
+		name := body[1:]
+		if array, subscript, rest, ok := splitSubscript(name); ok && rest == "" {
+			if subscript == "@" || subscript == "*" {
+				return strconv.Itoa(len(e.arrayValues(array, ctx))), nil
+			}
+			value, set, err := e.lookupElement(array, subscript, ctx)
+			if err != nil {
+				return "", err
+			}
+			if !set && ctx.IsOptionSet(constants.OptNounset) {
+				return "", domain.NewExpansionError(name + ": unbound variable")
+			}
+			return strconv.Itoa(len([]rune(value))), nil
+		}
+		if parameterNameLength(name) != len(name) {
+			return "", badSubstitution
+		}
+		if name == "@" || name == "*" {
+			return strconv.Itoa(len(ctx.PositionalArgs())), nil
+		}
+		value, err := e.requireParameter(name, ctx)
+		if err != nil {
+			return "", err
+		}
+		return strconv.Itoa(len([]rune(value))), nil
+	}
+
+	// ${!a[@]} - ключи массива
+	if array, subscript, rest, ok := splitSubscript(strings.TrimPrefix(body, "!")); ok &&
+		strings.HasPrefix(body, "!") && rest == "" && (subscript == "@" || subscript == "*") {
+		return strings.Join(e.arrayKeys(array, ctx), " "), nil
+	}
+
+	// ${!name} - косвенная ссылка
+	indirect := strings.HasPrefix(body, "!") && parameterNameLength(body[1:]) > 0
+	if indirect {
+		body = body[1:]
+	}
+
+	nameLen := parameterNameLength(body)
+	if nameLen == 0 {
+		return "", badSubstitution
+	}
+	name, rest := body[:nameLen], body[nameLen:]
+
+	// ${a[i]} - элемент массива
+	subscript := ""
+	if array, sub, after, ok := splitSubscript(body); ok && !indirect {
+		name, subscript, rest = array, sub, after
+	}
+
+	// label - имя параметра для сообщений об ошибках
+	label := name
+	value, set := e.lookupParameter(name, ctx)
+	if subscript != "" {
+		label = name + "[" + subscript + "]"
+		var err error
+		if value, set, err = e.lookupElement(name, subscript, ctx); err != nil {
+			return "", err
+		}
+	}
+	if indirect {
+		if !set {
+			return "", fmt.Errorf("%s: invalid indirect expansion", name)
+		}
+		if parameterNameLength(value) != len(value) {
+			return "", fmt.Errorf("%s: invalid variable name", value)
+		}
+		name = value
+		label = name
+		value, set = e.lookupParameter(name, ctx)
+	}
+
+	if rest == "" {
+		if !set && ctx.IsOptionSet(constants.OptNounset) {
+			return "", domain.NewExpansionError(label + ": unbound variable")
+		}
+		return value, nil
+	}
+
+	// Операторы подстановки значения по умолчанию: с : проверяется и пустое значение
+	for _, op := range []string{":-", ":=", ":?", ":+", "-", "=", "?", "+"} {
+		if !strings.HasPrefix(rest, op) {
+			continue
+		}
+		word := rest[len(op):]
+		missing := !set || (op[0] == ':' && value == "")
+
+		switch op[len(op)-1] {
+		case '-':
+			if missing {
+				return e.expandSubword(word, ctx)
+			}
+			return value, nil
+
+		case '=':
+			if !missing {
+				return value, nil
+			}
+			if !isNameStart(name[0]) || subscript == "@" || subscript == "*" {
+				return "", fmt.Errorf("$%s: cannot assign in this way", label)
+			}
+			assigned, err := e.expandSubword(word, ctx)
+			if err != nil {
+				return "", err
+			}
+			target := ctx.ResolveName(name)
+			if ctx.IsReadonly(target) {
+				return "", fmt.Errorf("%s: readonly variable", target)
+			}
+			// Присваивание выполняется как NAME=value: с учетом атрибутов -i, -l, -u
+			key := ""
+			if subscript != "" {
+				if key, err = e.ExpandSubscript(target, subscript, ctx); err != nil {
+					return "", err
+				}
+			}
+			return ctx.AssignValue(target, key, assigned, false, func(expr string) (int, error) {
+				return e.EvaluateArithmetic(expr, ctx)
+			})
+
+		case '?':
+			if !missing {
+				return value, nil
+			}
+			message, err := e.expandSubword(word, ctx)
+			if err != nil {
+				return "", err
+			}
+			if message == "" {
+				message = "parameter null or not set"
+			}
+			return "", domain.NewExpansionError(label + ": " + message)
+
+		case '+':
+			if missing {
+				return "", nil
+			}
+			return e.expandSubword(word, ctx)
+		}
+	}
+
+	if !set && ctx.IsOptionSet(constants.OptNounset) {
+		return "", domain.NewExpansionError(label + ": unbound variable")
+	}
+
+	switch {
+	case strings.HasPrefix(rest, "##"):
+		return e.removePattern(value, rest[2:], true, true, ctx)
+	case strings.HasPrefix(rest, "#"):
+		return e.removePattern(value, rest[1:], true, false, ctx)
+	case strings.HasPrefix(rest, "%%"):
+		return e.removePattern(value, rest[2:], false, true, ctx)
+	case strings.HasPrefix(rest, "%"):
+		return e.removePattern(value, rest[1:], false, false, ctx)
+	case strings.HasPrefix(rest, "/"):
+		return e.substitutePattern(value, rest[1:], ctx)
+	case strings.HasPrefix(rest, "^^"):
+		return e.modifyCase(value, rest[2:], unicode.ToUpper, true, ctx)
+	case strings.HasPrefix(rest, "^"):
+		return e.modifyCase(value, rest[1:], unicode.ToUpper, false, ctx)
+	case strings.HasPrefix(rest, ",,"):
+		return e.modifyCase(value, rest[2:], unicode.ToLower, true, ctx)
+	case strings.HasPrefix(rest, ","):
+		return e.modifyCase(value, rest[1:], unicode.ToLower, false, ctx)
+	case strings.HasPrefix(rest, "~~"):
+		return e.modifyCase(value, rest[2:], toggleCase, true, ctx)
+	case strings.HasPrefix(rest, "~"):
+		return e.modifyCase(value, rest[1:], toggleCase, false, ctx)
+	case strings.HasPrefix(rest, ":"):
+		return e.substring(value, rest[1:], ctx)
+	default:
+		return "", badSubstitution
+	}
+}
+
+// requireParameter возвращает значение параметра с учетом set -u
+func (e *WordExpanderAdapter) requireParameter(name string, ctx *domain.ExecutionContext) (string, error) {
+	value, set := e.lookupParameter(name, ctx)
+	if !set && ctx.IsOptionSet(constants.OptNounset) {
+		return "", domain.NewExpansionError(name + ": unbound variable")
+	}
+	return value, nil
+}
+
+// expandSubword раскрывает слово внутри ${...} и снимает с него кавычки
+func (e *WordExpanderAdapter) expandSubword(word string, ctx *domain.ExecutionContext) (string, error) {
+	fields, err := e.expandFields(word, false, ctx)
+	if err != nil {
+		return "", err
+	}
+
+	values := make([]string, 0, len(fields))
+	for _, field := range fields {
+		values = append(values, field.value.String())
+	}
+	return strings.Join(values, " "), nil
+}
+
+// compileSubpattern раскрывает слово внутри ${...} как шаблон: части
+// в кавычках сопоставляются буквально
+func (e *WordExpanderAdapter) compileSubpattern(word string, ctx *domain.ExecutionContext) (*globPattern, error) {
+	fields, err := e.expandFields(word, false, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns := make([]string, 0, len(fields))
+	for _, field := range fields {
+		patterns = append(patterns, field.pattern.String())
+	}
+	return compileGlob(strings.Join(patterns, " "), ctx.IsOptionSet(constants.OptExtglob), false), nil
+}
+
+// removePattern удаляет кратчайший или длиннейший префикс (#, ##) либо суффикс (%, %%)
+func (e *WordExpanderAdapter) removePattern(value, word string, prefix, longest bool, ctx *domain.ExecutionContext) (string, error) {
+	pattern, err := e.compileSubpattern(word, ctx)
+	if err != nil {
+		return "", err
+	}
+
+	runes := []rune(value)
+	n := len(runes)
+
+	for k := 0; k <= n; k++ {
+		// Для кратчайшего совпадения перебираем длины по возрастанию
+		length := k
+		if longest {
+			length = n - k
+		}
+
+		if prefix && pattern.Match(string(runes[:length])) {
+			return string(runes[length:]), nil
+		}
+		if !prefix && pattern.Match(string(runes[n-length:])) {
+			return string(runes[:n-length]), nil
+		}
+	}
+
+	return value, nil
+}
+
+// substitutePattern выполняет замены ${name/pat/rep}, ${name//pat/rep},
+// ${name/#pat/rep} и ${name/%pat/rep}
+func (e *WordExpanderAdapter) substitutePattern(value, spec string, ctx *domain.ExecutionContext) (string, error) {
+	all, anchorStart, anchorEnd := false, false, false
+	switch {
+	case strings.HasPrefix(spec, "/"):
+		all = true
+		spec = spec[1:]
+	case strings.HasPrefix(spec, "#"):
+		anchorStart = true
+		spec = spec[1:]
+	case strings.HasPrefix(spec, "%"):
+		anchorEnd = true
+		spec = spec[1:]
+	}
+
+	patternWord, replacementWord := splitSubstitution(spec)
+	if patternWord == "" {
+		return value, nil
+	}
+
+	pattern, err := e.compileSubpattern(patternWord, ctx)
+	if err != nil {
+		return "", err
+	}
+	replacement, err := e.expandSubword(replacementWord, ctx)
+	if err != nil {
+		return "", err
+	}
+
+	runes := []rune(value)
+	var result strings.Builder
+
+	for start := 0; start <= len(runes); {
+		matchEnd := -1
+		if !anchorStart || start == 0 {
+			// Ищем самое длинное совпадение, начинающееся в позиции start
+			for end := len(runes); end >= start; end-- {
+				if anchorEnd && end != len(runes) {
+					break
+				}
+				if pattern.Match(string(runes[start:end])) {
+					matchEnd = end
+					break
+				}
+			}
+		}
+
+		if matchEnd < 0 || (matchEnd == start && !anchorStart && !anchorEnd) {
+			if start < len(runes) {
+				result.WriteRune(runes[start])
+			}
+			start++
+			continue
+		}
+
+		result.WriteString(replacement)
+		if !all {
+			result.WriteString(string(runes[matchEnd:]))
+			return result.String(), nil
+		}
+		if matchEnd == start {
+			if start < len(runes) {
+				result.WriteRune(runes[start])
+			}
+			matchEnd++
+		}
+		start = matchEnd
+	}
+
+	return result.String(), nil
+}
+
+// splitSubstitution разделяет pat/rep по первому неэкранированному /
+func splitSubstitution(spec string) (string, string) {
+	for i := 0; i < len(spec); i++ {
+		switch spec[i] {
+		case '\\':
+			i++
+		case '\'':
+			if end := strings.IndexByte(spec[i+1:], '\''); end >= 0 {
+				i += end + 1
+			}
+		case '"':
+			i = skipDoubleQuoted(spec, i)
+		case '/':
+			return spec[:i], spec[i+1:]
+		}
+	}
+	return spec, ""
+}
+
+// modifyCase меняет регистр первого (^ , ~) или всех (^^ ,, ~~) символов,
+// подходящих под шаблон
+func (e *WordExpanderAdapter) modifyCase(value, word string, convert func(rune) rune, all bool, ctx *domain.ExecutionContext) (string, error) {
+	var pattern *globPattern
+	if word != "" {
+		var err error
+		if pattern, err = e.compileSubpattern(word, ctx); err != nil {
+			return "", err
+		}
+	}
+
+	runes := []rune(value)
+	for i, r := range runes {
+		if i > 0 && !all {
+			break
+		}
+		if pattern == nil || pattern.Match(string(r)) {
+			runes[i] = convert(r)
+		}
+	}
+	return string(runes), nil
+}
+
+// toggleCase переводит строчную букву в заглавную, а остальные - в строчные
+func toggleCase(r rune) rune {
+	if unicode.IsLower(r) {
+		return unicode.ToUpper(r)
+	}
+	return unicode.ToLower(r)
+}
+
+// substring выполняет ${name:offset} и ${name:offset:length}
+func (e *WordExpanderAdapter) substring(value, spec string, ctx *domain.ExecutionContext) (string, error) {
+	offsetExpr, lengthExpr, hasLength := strings.Cut(spec, ":")
+
+	offset, err := e.evalInteger(offsetExpr, ctx)
+	if err != nil {
+		return "", err
+	}
+
+	runes := []rune(value)
+	n := len(runes)
+
+	if offset < 0 {
+		offset += n
+		if offset < 0 {
+			return "", nil
+		}
+	}
+	if offset > n {
+		return "", nil
+	}
+
+	end := n
+	if hasLength {
+		length, err := e.evalInteger(lengthExpr, ctx)
+		if err != nil {
+			return "", err
+		}
+		if length < 0 {
+			end = n + length
+			if end < offset {
+				return "", fmt.Errorf("%s: substring expression < 0", lengthExpr)
+			}
+		} else {
+			end = min(offset+length, n)
+		}
+	}
+
+	return string(runes[offset:end]), nil
+}
+
+// evalInteger вычисляет смещение или длину подстроки как арифметическое выражение
+func (e *WordExpanderAdapter) evalInteger(expr string, ctx *domain.ExecutionContext) (int, error) {
+	return e.EvaluateArithmetic(expr, ctx)
+}
@@ -0,0 +1,184 @@
+package parser_adapters
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// globOptions - опции shell, влияющие на подстановку имен файлов
+type globOptions struct {
+	dotglob    bool
+	extglob    bool
+	nocaseglob bool
+}
+
+// expandPathname возвращает отсортированный список путей, соответствующих шаблону
+func expandPathname(pattern string, opts globOptions) []string {
+	base := ""
+	if strings.HasPrefix(pattern, "/") {
+		base = "/"
+		pattern = strings.TrimLeft(pattern, "/")
+	}
+
+	matches := globSegments(base, splitGlobSegments(pattern), opts)
+
+	// ** может найти один путь несколькими способами
+	seen := make(map[string]bool, len(matches))
+	unique := matches[:0]
+	for _, match := range matches {
+		if !seen[match] {
+			seen[match] = true
+			unique = append(unique, match)
+		}
+	}
+
+	sort.Strings(unique)
+	return unique
+}
+
+// splitGlobSegments разбивает шаблон на компоненты пути по неэкранированному /
+func splitGlobSegments(pattern string) []string {
+	var segments []string
+	start := 0
+
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '/':
+			segments = append(segments, pattern[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(segments, pattern[start:])
+}
+
+// globSegments рекурсивно сопоставляет компоненты пути с содержимым каталогов
+func globSegments(base string, segments []string, opts globOptions) []string {
+	if len(segments) == 0 {
+		return []string{base}
+	}
+
+	segment := segments[0]
+	rest := segments[1:]
+
+	// Завершающий / оставляет только каталоги
+	if segment == "" {
+		if len(rest) == 0 {
+			if info, err := os.Stat(base); err == nil && info.IsDir() {
+				return []string{base + "/"}
+			}
+			return nil
+		}
+		return globSegments(base, rest, opts)
+	}
+
+	if segment == "**" {
+		return globRecursive(base, rest, opts)
+	}
+
+	if !hasGlobMeta(segment, opts.extglob) {
+		path := joinGlobPath(base, unescapeGlob(segment))
+		if len(rest) == 0 {
+			if _, err := os.Lstat(path); err != nil {
+				return nil
+			}
+		}
+		return globSegments(path, rest, opts)
+	}
+
+	matcher := compileGlob(segment, opts.extglob, opts.nocaseglob)
+	var matches []string
+
+	for _, name := range readDirNames(base) {
+		if !matchesHidden(name, segment, opts) || !matcher.Match(name) {
+			continue
+		}
+
+		path := joinGlobPath(base, name)
+		if len(rest) > 0 {
+			if info, err := os.Stat(path); err != nil || !info.IsDir() {
+				continue
+			}
+		}
+		matches = append(matches, globSegments(path, rest, opts)...)
+	}
+
+	return matches
+}
+
+// globRecursive обрабатывает ** - ноль или более вложенных каталогов
+func globRecursive(base string, rest []string, opts globOptions) []string {
+	var matches []string
+
+	if len(rest) == 0 {
+		// ** в конце шаблона совпадает со всеми файлами и каталогами
+		for _, name := range readDirNames(base) {
+			if strings.HasPrefix(name, ".") && !opts.dotglob {
+				continue
+			}
+			path := joinGlobPath(base, name)
+			matches = append(matches, path)
+			if info, err := os.Lstat(path); err == nil && info.IsDir() {
+				matches = append(matches, globRecursive(path, rest, opts)...)
+			}
+		}
+		return matches
+	}
+
+	matches = append(matches, globSegments(base, rest, opts)...)
+
+	for _, name := range readDirNames(base) {
+		if strings.HasPrefix(name, ".") && !opts.dotglob {
+			continue
+		}
+		path := joinGlobPath(base, name)
+		// Символические ссылки на каталоги не обходим, чтобы избежать циклов
+		if info, err := os.Lstat(path); err == nil && info.IsDir() {
+			matches = append(matches, globRecursive(path, rest, opts)...)
+		}
+	}
+
+	return matches
+}
+
+// matchesHidden проверяет правило для скрытых файлов: точку в начале имени
+// нужно указать явно, если не включена опция dotglob
+func matchesHidden(name, segment string, opts globOptions) bool {
+	if !strings.HasPrefix(name, ".") || opts.dotglob {
+		return true
+	}
+	return strings.HasPrefix(segment, ".") || strings.HasPrefix(segment, "\\.")
+}
+
+// readDirNames возвращает имена записей каталога
+func readDirNames(dir string) []string {
+	if dir == "" {
+		dir = "."
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+// joinGlobPath соединяет каталог и имя, сохраняя относительную форму пути
+func joinGlobPath(base, name string) string {
+	switch base {
+	case "":
+		return name
+	case "/":
+		return "/" + name
+	default:
+		return strings.TrimSuffix(base, "/") + "/" + name
+	}
+}
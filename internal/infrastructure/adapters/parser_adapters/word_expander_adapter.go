@@ -0,0 +1,530 @@
+package parser_adapters
+
+import (
+	"fmt"
+	"minishell/internal/domain"
+	"minishell/pkg/constants"
+	"os/user"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// WordExpanderAdapter - адаптер для раскрытия слов команды
+type WordExpanderAdapter struct{}
+
+// NewWordExpanderAdapter создает новый адаптер раскрытия слов
+func NewWordExpanderAdapter() *WordExpanderAdapter {
+	return &WordExpanderAdapter{}
+}
+
+// wordField - поле, полученное при раскрытии слова. pattern повторяет value,
+// но символы из кавычек в нем экранированы и не участвуют в подстановке имен файлов
+type wordField struct {
+	value   strings.Builder
+	pattern strings.Builder
+	// quoted означает, что в поле есть части в кавычках, поэтому даже пустое
+	// поле остается отдельным аргументом
+	quoted bool
+}
+
+// writeQuoted добавляет текст, защищенный кавычками
+func (f *wordField) writeQuoted(s string) {
+	f.value.WriteString(s)
+	f.pattern.WriteString(escapeGlob(s))
+	f.quoted = true
+}
+
+// writeUnquoted добавляет текст вне кавычек
+func (f *wordField) writeUnquoted(s string) {
+	f.value.WriteString(s)
+	f.pattern.WriteString(s)
+}
+
+// ExpandWords выполняет подстановку фигурных скобок и переменных, снимает
+// кавычки и подставляет имена файлов
+func (e *WordExpanderAdapter) ExpandWords(words []string, ctx *domain.ExecutionContext) ([]string, error) {
+	opts := e.globOptions(ctx)
+	result := make([]string, 0, len(words))
+
+	for _, word := range words {
+		for _, braced := range expandBraces(word) {
+			expanded, err := e.expandPathnames(braced, ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, expanded...)
+		}
+	}
+
+	return result, nil
+}
+
+// ExpandValue раскрывает значение присваивания: без подстановки фигурных
+// скобок и имен файлов, с раскрытием тильды в начале и после каждого :
+func (e *WordExpanderAdapter) ExpandValue(word string, ctx *domain.ExecutionContext) (string, error) {
+	fields, err := e.expandFields(word, true, ctx)
+	if err != nil {
+		return "", err
+	}
+
+	values := make([]string, 0, len(fields))
+	for _, field := range fields {
+		values = append(values, field.value.String())
+	}
+	return strings.Join(values, " "), nil
+}
+
+// ExpandWord раскрывает одно слово, например имя файла для редиректа
+func (e *WordExpanderAdapter) ExpandWord(word string, ctx *domain.ExecutionContext) (string, error) {
+	braced := expandBraces(word)
+	if len(braced) != 1 {
+		return "", fmt.Errorf("%s: ambiguous redirect", word)
+	}
+
+	expanded, err := e.expandPathnames(braced[0], ctx, e.globOptions(ctx))
+	if err != nil {
+		return "", err
+	}
+	if len(expanded) != 1 {
+		return "", fmt.Errorf("%s: ambiguous redirect", word)
+	}
+	return expanded[0], nil
+}
+
+// expandPathnames раскрывает слово и подставляет имена файлов по шаблону.
+// Пустые поля без кавычек (например, $UNSET) удаляются.
+func (e *WordExpanderAdapter) expandPathnames(word string, ctx *domain.ExecutionContext, opts globOptions) ([]string, error) {
+	fields, err := e.expandFields(word, false, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, field := range fields {
+		value := field.value.String()
+		if value == "" && !field.quoted {
+			continue
+		}
+
+		pattern := field.pattern.String()
+		if !hasGlobMeta(pattern, opts.extglob) {
+			result = append(result, value)
+			continue
+		}
+
+		matches := expandPathname(pattern, opts)
+		switch {
+		case len(matches) > 0:
+			result = append(result, matches...)
+		case ctx.IsOptionSet(constants.OptFailglob):
+			return nil, fmt.Errorf("no match: %s", word)
+		case ctx.IsOptionSet(constants.OptNullglob):
+			continue
+		default:
+			result = append(result, value)
+		}
+	}
+
+	return result, nil
+}
+
+// globOptions собирает опции подстановки имен файлов из контекста
+func (e *WordExpanderAdapter) globOptions(ctx *domain.ExecutionContext) globOptions {
+	return globOptions{
+		dotglob:    ctx.IsOptionSet(constants.OptDotglob),
+		extglob:    ctx.IsOptionSet(constants.OptExtglob),
+		nocaseglob: ctx.IsOptionSet(constants.OptNocaseglob),
+	}
+}
+
+// expandFields раскрывает тильду и параметры и снимает кавычки в слове.
+// Обычно результат - одно поле, но $@ и ${a[@]} дают по полю на каждый элемент.
+// value означает, что слово - значение присваивания.
+func (e *WordExpanderAdapter) expandFields(word string, value bool, ctx *domain.ExecutionContext) ([]*wordField, error) {
+	field := &wordField{}
+	fields := []*wordField{field}
+
+	// appendValues дописывает первое значение в текущее поле, а остальные - в новые
+	appendValues := func(values []string, quoted bool) {
+		for k, value := range values {
+			if k > 0 {
+				field = &wordField{}
+				fields = append(fields, field)
+			}
+			if quoted {
+				field.writeQuoted(value)
+			} else {
+				field.writeUnquoted(value)
+			}
+		}
+	}
+
+	// В словах-присваиваниях тильда раскрывается также после = и после каждого :
+	assignment := value || isAssignmentWord(word)
+	seenEquals := value
+	tildeAllowed := true
+
+	for i := 0; i < len(word); i++ {
+		ch := word[i]
+
+		if ch == '~' && tildeAllowed {
+			if home, consumed, ok := e.expandTilde(word[i:], assignment, ctx); ok {
+				field.writeQuoted(home)
+				i += consumed - 1
+				tildeAllowed = false
+				continue
+			}
+		}
+		tildeAllowed = false
+
+		switch ch {
+		case '\'':
+			end := strings.IndexByte(word[i+1:], '\'')
+			if end < 0 {
+				field.writeQuoted(word[i+1:])
+				return fields, nil
+			}
+			field.writeQuoted(word[i+1 : i+1+end])
+			i += end + 1
+
+		case '"':
+			// "$@" и "${a[@]}" без элементов не дают ни одного поля
+			if values, star, consumed, ok, err := e.expandList(word[i+1:], ctx); ok && err == nil &&
+				!star && len(values) == 0 && strings.HasPrefix(word[i+1+consumed:], `"`) {
+				i += consumed + 1
+				continue
+			}
+
+			field.writeQuoted("")
+			i++
+			for i < len(word) && word[i] != '"' {
+				switch {
+				case word[i] == '\\' && i+1 < len(word) && strings.IndexByte("$`\"\\", word[i+1]) >= 0:
+					field.writeQuoted(word[i+1 : i+2])
+					i += 2
+				case word[i] == '$':
+					if values, star, consumed, ok, err := e.expandList(word[i:], ctx); ok {
+						if err != nil {
+							return nil, err
+						}
+						if star {
+							field.writeQuoted(strings.Join(values, e.fieldSeparator(ctx)))
+						} else {
+							appendValues(values, true)
+						}
+						i += consumed
+						continue
+					}
+					value, consumed, err := e.expandDollar(word[i:], ctx)
+					if err != nil {
+						return nil, err
+					}
+					field.writeQuoted(value)
+					i += consumed
+				default:
+					field.writeQuoted(word[i : i+1])
+					i++
+				}
+			}
+
+		case '\\':
+			if i+1 < len(word) {
+				i++
+				field.writeQuoted(word[i : i+1])
+			}
+
+		case '$':
+			if values, _, consumed, ok, err := e.expandList(word[i:], ctx); ok {
+				if err != nil {
+					return nil, err
+				}
+				appendValues(values, false)
+				i += consumed - 1
+				continue
+			}
+			value, consumed, err := e.expandDollar(word[i:], ctx)
+			if err != nil {
+				return nil, err
+			}
+			field.writeUnquoted(value)
+			i += consumed - 1
+
+		default:
+			if assignment && ((ch == '=' && !seenEquals) || (ch == ':' && seenEquals)) {
+				seenEquals = true
+				tildeAllowed = true
+			}
+			field.writeUnquoted(word[i : i+1])
+		}
+	}
+
+	return fields, nil
+}
+
+// expandList раскрывает конструкции, дающие список значений: $@, $*, ${@}, ${*},
+// ${name[@]}, ${name[*]}, ${!name[@]} и срезы вида ${@:offset:length}.
+// star сообщает, что это форма с *.
+func (e *WordExpanderAdapter) expandList(s string, ctx *domain.ExecutionContext) (values []string, star bool, consumed int, ok bool, err error) {
+	if strings.HasPrefix(s, "$@") || strings.HasPrefix(s, "$*") {
+		return ctx.PositionalArgs(), s[1] == '*', 2, true, nil
+	}
+	if !strings.HasPrefix(s, "${") {
+		return nil, false, 0, false, nil
+	}
+
+	end := findParameterEnd(s)
+	if end < 0 {
+		return nil, false, 0, false, nil
+	}
+	body := s[2:end]
+	consumed = end + 1
+
+	var list []string
+	var spec string
+	positional := false
+
+	switch {
+	case strings.HasPrefix(body, "@") || strings.HasPrefix(body, "*"):
+		star = body[0] == '*'
+		spec = body[1:]
+		list = ctx.PositionalArgs()
+		positional = true
+
+	default:
+		keys := strings.HasPrefix(body, "!")
+		name, subscript, rest, hasSubscript := splitSubscript(strings.TrimPrefix(body, "!"))
+		if !hasSubscript || (subscript != "@" && subscript != "*") || (keys && rest != "") {
+			return nil, false, 0, false, nil
+		}
+		star = subscript == "*"
+		spec = rest
+		if keys {
+			list = e.arrayKeys(name, ctx)
+		} else {
+			list = e.arrayValues(name, ctx)
+		}
+	}
+
+	if spec == "" {
+		return list, star, consumed, true, nil
+	}
+	if spec[0] != ':' || strings.HasPrefix(spec, ":-") || strings.HasPrefix(spec, ":=") ||
+		strings.HasPrefix(spec, ":?") || strings.HasPrefix(spec, ":+") {
+		// Прочие операторы применяются к списку как к одной строке
+		return nil, false, 0, false, nil
+	}
+
+	// Срез позиционных параметров: смещение 0 включает $0
+	if positional {
+		list = append([]string{ctx.ShellName}, list...)
+	}
+	offsetExpr, lengthExpr, hasLength := strings.Cut(spec[1:], ":")
+
+	offset, err := e.evalInteger(offsetExpr, ctx)
+	if err != nil {
+		return nil, star, consumed, true, err
+	}
+	if offset < 0 {
+		offset += len(list)
+	}
+	if offset < 0 || offset >= len(list) {
+		return nil, star, consumed, true, nil
+	}
+
+	last := len(list)
+	if hasLength {
+		length, err := e.evalInteger(lengthExpr, ctx)
+		if err != nil {
+			return nil, star, consumed, true, err
+		}
+		if length < 0 {
+			return nil, star, consumed, true, fmt.Errorf("%s: substring expression < 0", lengthExpr)
+		}
+		last = min(offset+length, len(list))
+	}
+
+	return list[offset:last], star, consumed, true, nil
+}
+
+// fieldSeparator возвращает разделитель для "$*" - первый символ IFS
+func (e *WordExpanderAdapter) fieldSeparator(ctx *domain.ExecutionContext) string {
+	ifs, ok := ctx.LookupEnv("IFS")
+	if !ok {
+		return " "
+	}
+	if ifs == "" {
+		return ""
+	}
+	return ifs[:1]
+}
+
+// expandTilde раскрывает префикс ~, ~user, ~+ или ~- в начале s и возвращает
+// результат и длину префикса. Префикс с кавычками не раскрывается.
+func (e *WordExpanderAdapter) expandTilde(s string, assignment bool, ctx *domain.ExecutionContext) (string, int, bool) {
+	end := 1
+	for end < len(s) && s[end] != '/' && !(assignment && s[end] == ':') {
+		end++
+	}
+
+	prefix := s[1:end]
+	if strings.ContainsAny(prefix, "'\"\\$`") {
+		return "", 0, false
+	}
+
+	switch prefix {
+	case "":
+		if home, ok := ctx.LookupEnv("HOME"); ok {
+			return home, end, true
+		}
+		if current, err := user.Current(); err == nil {
+			return current.HomeDir, end, true
+		}
+		return "", 0, false
+
+	case "+":
+		if pwd, ok := ctx.LookupEnv("PWD"); ok {
+			return pwd, end, true
+		}
+		return ctx.CurrentDir, end, true
+
+	case "-":
+		if oldpwd, ok := ctx.LookupEnv("OLDPWD"); ok {
+			return oldpwd, end, true
+		}
+		return "", 0, false
+
+	default:
+		u, err := user.Lookup(prefix)
+		if err != nil {
+			return "", 0, false
+		}
+		return u.HomeDir, end, true
+	}
+}
+
+// isAssignmentWord проверяет, имеет ли слово вид NAME=value или NAME+=value
+func isAssignmentWord(word string) bool {
+	eq := strings.IndexByte(word, '=')
+	if eq <= 0 {
+		return false
+	}
+
+	name := strings.TrimSuffix(word[:eq], "+")
+	if name == "" || !isNameStart(name[0]) {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		if !isNameChar(name[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// expandDollar раскрывает подстановку, начинающуюся с $, и возвращает
+// значение и количество прочитанных байт
+func (e *WordExpanderAdapter) expandDollar(s string, ctx *domain.ExecutionContext) (string, int, error) {
+	if len(s) < 2 {
+		return "$", 1, nil
+	}
+
+	switch {
+	case s[1] == '{':
+		end := findParameterEnd(s)
+		if end < 0 {
+			return "", 0, fmt.Errorf("%s: bad substitution", s)
+		}
+		value, err := e.expandParameter(s[2:end], ctx)
+		return value, end + 1, err
+
+	case isDigit(s[1]) || strings.IndexByte(specialParameters, s[1]) >= 0:
+		value, err := e.requireParameter(s[1:2], ctx)
+		return value, 2, err
+
+	case isNameStart(s[1]):
+		end := 2
+		for end < len(s) && isNameChar(s[end]) {
+			end++
+		}
+		value, err := e.requireParameter(s[1:end], ctx)
+		return value, end, err
+
+	default:
+		return "$", 1, nil
+	}
+}
+
+// specialParameters - односимвольные специальные параметры
+const specialParameters = "?$!#-@*"
+
+// lookupParameter возвращает значение переменной, позиционного или
+// специального параметра и признак того, что параметр установлен
+func (e *WordExpanderAdapter) lookupParameter(name string, ctx *domain.ExecutionContext) (string, bool) {
+	switch name {
+	case "?":
+		return strconv.Itoa(ctx.LastExitCode), true
+	case "$":
+		return strconv.Itoa(ctx.ShellPID), true
+	case "!":
+		if ctx.LastBackgroundPID == 0 {
+			return "", false
+		}
+		return strconv.Itoa(ctx.LastBackgroundPID), true
+	case "#":
+		return strconv.Itoa(len(ctx.PositionalArgs())), true
+	case "-":
+		return e.optionFlags(ctx), true
+	case "@", "*":
+		args := ctx.PositionalArgs()
+		return strings.Join(args, e.fieldSeparator(ctx)), len(args) > 0
+	case "0":
+		return ctx.ShellName, true
+	}
+
+	// Имя массива без индекса означает элемент 0
+	name = ctx.ResolveName(name)
+	if arr, ok := ctx.GetArray(name); ok {
+		return arr.Get("0")
+	}
+
+	if n, err := strconv.Atoi(name); err == nil {
+		args := ctx.PositionalArgs()
+		if n >= 1 && n <= len(args) {
+			return args[n-1], true
+		}
+		return "", false
+	}
+	return ctx.LookupEnv(name)
+}
+
+// optionFlags возвращает значение $- - буквы включенных опций set
+// и i для интерактивного shell
+func (e *WordExpanderAdapter) optionFlags(ctx *domain.ExecutionContext) string {
+	flags := make([]byte, 0, len(constants.SetOptionFlags)+1)
+	for flag, name := range constants.SetOptionFlags {
+		if ctx.IsOptionSet(name) {
+			flags = append(flags, flag)
+		}
+	}
+	if ctx.Interactive {
+		flags = append(flags, 'i')
+	}
+	slices.Sort(flags)
+	return string(flags)
+}
+
+// isDigit проверяет, является ли символ цифрой
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+// isNameStart проверяет, может ли символ начинать имя переменной
+func isNameStart(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+// isNameChar проверяет, может ли символ входить в имя переменной
+func isNameChar(ch byte) bool {
+	return isNameStart(ch) || isDigit(ch)
+}
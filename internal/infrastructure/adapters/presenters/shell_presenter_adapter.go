@@ -13,9 +13,9 @@ func NewShellPresenterAdapter() *ShellPresenterAdapter {
 	return &ShellPresenterAdapter{}
 }
 
-// ShowPrompt показывает приглашение командной строки
+// ShowPrompt показывает приглашение командной строки в потоке ошибок
 func (p *ShellPresenterAdapter) ShowPrompt(prompt string) {
-	fmt.Print(prompt)
+	fmt.Fprint(os.Stderr, prompt)
 }
 
 // ShowOutput показывает вывод команды
@@ -23,6 +23,12 @@ func (p *ShellPresenterAdapter) ShowOutput(output string) {
 	fmt.Println(output)
 }
 
+// ShowMessage показывает служебное сообщение shell в потоке ошибок, чтобы
+// оно не смешивалось с выводом команд
+func (p *ShellPresenterAdapter) ShowMessage(message string) {
+	fmt.Fprintln(os.Stderr, message)
+}
+
 // ShowError показывает ошибку
 func (p *ShellPresenterAdapter) ShowError(error string) {
 	fmt.Fprintln(os.Stderr, "Error:", error)
@@ -12,12 +12,129 @@ const (
 	OperatorOutput = ">"
 	OperatorAppend = ">>"
 	OperatorInput  = "<"
+	OperatorSeq    = ";"
+	OperatorBg     = "&"
 
 	// Builtin commands
-	CmdCD   = "cd"
-	CmdPWD  = "pwd"
-	CmdEcho = "echo"
-	CmdKill = "kill"
-	CmdPS   = "ps"
-	CmdExit = "exit"
+	CmdCD       = "cd"
+	CmdPWD      = "pwd"
+	CmdEcho     = "echo"
+	CmdKill     = "kill"
+	CmdPS       = "ps"
+	CmdExit     = "exit"
+	CmdLocal    = "local"
+	CmdReturn   = "return"
+	CmdAlias    = "alias"
+	CmdUnalias  = "unalias"
+	CmdShopt    = "shopt"
+	CmdSet      = "set"
+	CmdShift    = "shift"
+	CmdUnset    = "unset"
+	CmdDeclare  = "declare"
+	CmdTypeset  = "typeset"
+	CmdExport   = "export"
+	CmdEnv      = "env"
+	CmdReadonly = "readonly"
+	CmdSource   = "source"
+	CmdDot      = "."
+	CmdHistory  = "history"
+	CmdComplete = "complete"
+	CmdCompgen  = "compgen"
+
+	// Functions
+	MaxFunctionDepth = 1000
+
+	// Sourced files
+	MaxSourceDepth = 100
+
+	// Brace expansion: longest {x..y} sequence
+	MaxBraceSequence = 1000000
+
+	// Command search path when PATH is not set (as in execvp)
+	DefaultPath = "/usr/local/bin:/usr/bin:/bin"
+
+	// History
+	DefaultHistoryFile = ".minishell_history"
+	DefaultHistorySize = 500
+
+	// Option completion cache
+	DefaultCacheDir = ".cache"
+	OptionsCacheDir = "minishell/options"
+
+	// Syntax highlighting
+	HighlightColorsVar = "MINISHELL_COLORS"
+	HighlightNone      = "none"
+
+	// Shell options (shopt)
+	OptDotglob    = "dotglob"
+	OptExtglob    = "extglob"
+	OptFailglob   = "failglob"
+	OptNocaseglob = "nocaseglob"
+	OptNullglob   = "nullglob"
+
+	// Shell options (set -o)
+	OptEmacs      = "emacs"
+	OptHistexpand = "histexpand"
+	OptHistory    = "history"
+	OptNounset    = "nounset"
+	OptVi         = "vi"
+
+	// Completion actions (complete -A)
+	CompActionAlias     = "alias"
+	CompActionBuiltin   = "builtin"
+	CompActionCommand   = "command"
+	CompActionDirectory = "directory"
+	CompActionExport    = "export"
+	CompActionFile      = "file"
+	CompActionFunction  = "function"
+	CompActionVariable  = "variable"
+
+	// Completion options (complete -o)
+	CompOptBashdefault = "bashdefault"
+	CompOptDefault     = "default"
+	CompOptDirnames    = "dirnames"
+	CompOptFilenames   = "filenames"
+	CompOptNospace     = "nospace"
+	CompOptPlusdirs    = "plusdirs"
 )
+
+// ShoptOptions - опции, управляемые командой shopt
+var ShoptOptions = []string{
+	OptDotglob,
+	OptExtglob,
+	OptFailglob,
+	OptNocaseglob,
+	OptNullglob,
+}
+
+// CompletionOptions - параметры, задаваемые complete -o
+var CompletionOptions = []string{
+	CompOptBashdefault,
+	CompOptDefault,
+	CompOptDirnames,
+	CompOptFilenames,
+	CompOptNospace,
+	CompOptPlusdirs,
+}
+
+// SetOptionFlags - однобуквенные флаги команды set и соответствующие им опции
+var SetOptionFlags = map[byte]string{
+	'H': OptHistexpand,
+	'u': OptNounset,
+}
+
+// SetOptions - опции, управляемые командой set -o
+var SetOptions = []string{
+	OptEmacs,
+	OptHistexpand,
+	OptHistory,
+	OptNounset,
+	OptVi,
+}
+
+// EditingModeOptions - взаимоисключающие режимы редактирования строки
+var EditingModeOptions = []string{OptEmacs, OptVi}
+
+// DeclarationBuiltins - команды, аргументы-присваивания которых
+// раскрываются как присваивания, а не как обычные слова
+var DeclarationBuiltins = []string{CmdDeclare, CmdTypeset, CmdExport, CmdLocal, CmdReadonly}
@@ -16,3 +16,9 @@ func SplitFields(s string, sep string) []string {
 func SplitEnvVar(env string) []string {
 	return strings.SplitN(env, "=", 2)
 }
+
+// ShellQuote заключает строку в одинарные кавычки так, чтобы ее можно было
+// повторно прочитать shell
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
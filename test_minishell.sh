@@ -9,6 +9,9 @@ TEST_FILE="$TEST_DIR/test_file"
 echo "line1" > $TEST_FILE
 echo "line2" >> $TEST_FILE
 echo "line3" >> $TEST_FILE
+mkdir -p $TEST_DIR/bin
+printf '#!/bin/sh\necho "ms_tool: $*"\n' > $TEST_DIR/bin/ms_tool
+chmod +x $TEST_DIR/bin/ms_tool
 
 run_test() {
     echo ">>> Testing: $1"
@@ -47,6 +50,7 @@ run_test "echo hello | wc -c"
 run_test "cat $TEST_FILE | grep line | wc -l"
 run_test "ps | grep $$ | wc -l"  # testing with real process
 run_test "echo test1 test2 | wc -w"
+run_test "x=1; f() { x=2; }; f | cat; cd / | cat; echo \$x; pwd"
 
 echo -e "\n4. Testing LOGICAL OPERATORS (&& and ||):"
 run_test "true && echo 'AND success'"
@@ -86,7 +90,147 @@ run_test "cd $TEST_DIR && ls -la | head -3 && echo 'success' || echo 'failure'"
 run_test "echo \$HOME | wc -c && echo 'var worked' || echo 'var failed'"
 run_test "cat $TEST_FILE | grep line1 > $TEST_FILE.found && cat $TEST_FILE.found || echo 'not found'"
 
-echo -e "\n9. Testing EXIT COMMAND:"
+echo -e "\n9. Testing FUNCTIONS:"
+run_test "greet() { echo hello \$1; }; greet world"
+run_test "function f { local x=inner; echo \$x; }\nx_outer() { f; echo \$x; }; x_outer"
+run_test "f() { return 3; echo 'SHOULD NOT APPEAR'; }; f || echo 'returned non-zero'"
+run_test "f() { return; }; false; f; echo \$?"
+run_test "f() {\necho line1\necho line2\n}\nf | wc -l"
+run_test "rec() { rec; }; rec 2>&1; echo 'recursion limited'"
+
+echo -e "\n10. Testing ALIASES:"
+run_test "alias ll='ls -d'\nll /tmp"
+run_test "alias e='echo ' w=world\ne w"
+run_test "alias g='echo it'\\''s'\nalias"
+run_test "alias x=y\nunalias -a\nalias"
+
+echo -e "\n11. Testing GLOBBING:"
+run_test "cd $TEST_DIR && echo test_file*"
+run_test "cd $TEST_DIR && echo 'test_file*' test_file\\*"
+run_test "cd $TEST_DIR && echo *.none"
+run_test "cd $TEST_DIR && shopt -s nullglob && echo *.none end"
+run_test "cd $TEST_DIR && shopt -s failglob && echo *.none 2>&1"
+run_test "echo /etc/pass??"
+
+echo -e "\n12. Testing BRACE EXPANSION:"
+run_test "echo a{b,c}d {x,y}{1,2}"
+run_test "echo {1..10..3} {01..03} {a..e..2}"
+run_test "echo {9223372036854775806..9223372036854775807} {1..2000000}"
+run_test "echo {a,{b,c}d} {a} '{x,y}'"
+run_test "mkdir -p $TEST_DIR/src/{api,db}/{v1,v2} && ls $TEST_DIR/src/db"
+
+echo -e "\n13. Testing TILDE EXPANSION:"
+run_test "echo ~ ~/projects '~' \\~"
+run_test "echo ~root PATH=~/bin:~/sbin"
+run_test "cd /tmp && cd $TEST_DIR && echo ~+ ~-"
+
+echo -e "\n14. Testing PARAMETER EXPANSION:"
+run_test "echo \${NONEXISTENT_VAR:-default} \${HOME:+set} \${#HOME}"
+run_test "echo \${NEW_VAR:=assigned} \$NEW_VAR"
+run_test "declare -l L; declare -i I; echo \${L:=ABC} \${I:=2+3} \$L \$I"
+run_test "echo \${NONEXISTENT_VAR:?is required} 2>&1"
+run_test "f() { local p=/usr/lib/file.tar.gz; echo \${p##*/} \${p%.*} \${p%%.*} \${p//l/L} \${p:5:3} \${p^^}; }; f"
+run_test "set -u; echo \$NONEXISTENT_VAR 2>&1"
+run_test "set -u\necho \$NONEXISTENT_VAR\necho not printed"
+run_test "f() { echo \${NONEXISTENT_VAR:?is required}; echo not printed; }; f; echo not printed"
+run_test "set -u; echo \$NONEXISTENT_VAR | cat; echo after pipeline"
+run_test "X=aBc; echo \${X~} \${X~~}"
+
+echo -e "\n15. Testing SPECIAL PARAMETERS:"
+run_test "false; echo \$?; true; echo \$?"
+run_test "f() { echo \$# \"\$*\"; printf '<%s>' \"\$@\"; echo; }; f 'a b' c"
+run_test "echo \$0 \$- && test \$\$ -gt 0 && echo pid"
+run_test "sleep 0 & echo \${!:+background started}"
+run_test "f() { echo \"f: \$*\"; }; f \${MS_BG:=once} & echo \$MS_BG"
+
+echo -e "\n16. Testing POSITIONAL PARAMETERS:"
+run_test "set -- a b c d e f g h i j k; echo \$1 \$9 \${10} \${11} \$#"
+run_test "set -- a b c; shift; echo \$1 \$#; shift 5; echo \$? \$*"
+run_test "set -- a b; set -; echo \$#; set - x; echo \$# \$1"
+run_test "f() { shift; set -- x \"\$@\"; echo \$@; }; set -- outer; f a b; echo \$1"
+
+echo -e "\n17. Testing ARRAYS:"
+run_test "a=(x y z); a+=(w 'v u'); echo \${a[1]} \${#a[@]} \${a[-1]}; printf '<%s>' \"\${a[@]}\"; echo"
+run_test "a=(x y z); unset 'a[1]'; a[10]=ten; echo \${!a[@]} \${a[@]}"
+run_test "declare -A m=([port]=22 [user]=root); m[host]=example.org; echo \${!m[@]} \${m[host]} \${#m[@]}"
+
+echo -e "\n18. Testing EXPORT, UNSET, SET AND ENV:"
+run_test "export MS_VAR=value; sh -c 'echo child: \$MS_VAR'; export -n MS_VAR; sh -c 'echo child: \$MS_VAR'"
+run_test "export MS_VAR=value; export -p | grep MS_VAR; unset MS_VAR; echo \"[\$MS_VAR]\""
+run_test "f() { echo f; }; unset -f f; f 2>&1"
+run_test "a=(1 '2 3'); set | grep '^a='"
+run_test "env -i A=1 B=2 env; env -i sh -c 'echo \"[\$HOME]\"'"
+run_test "ms_tool 2>&1; echo \$?; export PATH=$TEST_DIR/bin:\$PATH; ms_tool exported; env -i PATH=$TEST_DIR/bin ms_tool env"
+
+echo -e "\n19. Testing VARIABLE ASSIGNMENTS:"
+run_test "MS_VAR='a b'; MS_VAR+=' c'; echo \"\$MS_VAR\""
+run_test "MS_VAR=temp sh -c 'echo child: \$MS_VAR'; echo \"shell: [\$MS_VAR]\""
+run_test "PATH=$TEST_DIR/bin ms_tool prefix; ms_tool 2>&1"
+run_test "f() { echo \"in f: \$MS_VAR\"; }; MS_VAR=42 f; echo \"after: [\$MS_VAR]\""
+
+echo -e "\n20. Testing VARIABLE ATTRIBUTES:"
+run_test "readonly MS_CONST=1; MS_CONST=2; unset MS_CONST; echo \$MS_CONST"
+run_test "readonly MS_CONST=1; f() { local MS_CONST=2; echo \"rc=\$? in: \$MS_CONST\"; }; f"
+run_test "declare -i n=2+3; n+=4; echo \$n; declare -l low=HeLLo; declare -u up=world; echo \$low \$up"
+run_test "x=1; declare -n ref=x; ref=42; echo \$x; declare -p ref x"
+run_test "f() { local -i c=1+1; declare d=local; echo \$c \$d; }; d=global; f; echo \$d"
+
+echo -e "\n21. Testing SCRIPTS AND -c:"
+SCRIPT_FILE="$TEST_DIR/script.sh"
+MINISHELL_BIN="$TEST_DIR/minishell"
+go build -o $MINISHELL_BIN cmd/minishell/main.go
+printf '#!/usr/bin/env minishell\necho "$0: $# args: $*"\ngreet() {\n  echo "hello $1"\n}\ngreet "$2"\nexit 7\necho never\n' > $SCRIPT_FILE
+echo ">>> Testing: minishell script.sh a b"
+$MINISHELL_BIN $SCRIPT_FILE a b 2>&1; echo "exit status: $?"
+echo "---"
+echo ">>> Testing: minishell -c 'echo \$0 \$1; false' name arg"
+$MINISHELL_BIN -c 'echo $0 $1; false' name arg 2>&1; echo "exit status: $?"
+echo "---"
+echo ">>> Testing: echo 'echo \$1 \$#' | minishell -s x y"
+echo 'echo $1 $#' | $MINISHELL_BIN -s x y 2>&1
+echo "---"
+
+echo -e "\n22. Testing SOURCE:"
+LIB_FILE="$TEST_DIR/lib.sh"
+printf 'LIB_VAR=loaded\nlib_hello() { echo "lib hello $1"; }\necho "args: $*"\ncd %s\nreturn 3\necho never\n' "$TEST_DIR" > $LIB_FILE
+run_test "set -- outer; source $LIB_FILE a b; echo \$? \$LIB_VAR \$*; lib_hello x; pwd"
+run_test "PATH=$TEST_DIR:\$PATH; . lib.sh; echo \$?"
+run_test "source $TEST_DIR/nosuch.sh; echo \$?; source $TEST_DIR; echo \$?"
+
+echo -e "\n23. Testing INTERACTIVE MODE DETECTION:"
+echo ">>> Testing: echo 'echo \$-' | minishell (no prompt on stdout)"
+echo 'echo "[$-]"' | $MINISHELL_BIN
+echo "---"
+echo ">>> Testing: echo 'echo \$-' | minishell -i 2>/dev/null"
+echo 'echo "[$-]"' | $MINISHELL_BIN -i 2>/dev/null
+echo "---"
+echo ">>> Testing: printf 'echo a\\n!!\\n' | minishell -i 2>/dev/null (expanded line not on stdout)"
+printf 'echo a\n!!\n' | $MINISHELL_BIN -i 2>/dev/null
+echo "---"
+
+echo -e "\n24. Testing EDITING MODE OPTIONS:"
+run_test "set -o vi; set -o | grep -E '^(emacs|vi) '; set -o emacs; set +o | grep -E ' (emacs|vi)\$'"
+
+echo -e "\n25. Testing HISTORY:"
+run_test "set -o history\necho one\necho two\nhistory\nhistory -d 2\nhistory 2"
+run_test "set -o history\necho saved\nhistory -w $TEST_DIR/history\nhistory -c\nhistory -r $TEST_DIR/history\nhistory\nhistory -d 99; echo \$?"
+run_test "set -o history\nf() {\n  echo multi\n}\nhistory -w $TEST_DIR/history\nhistory -c\nhistory -r $TEST_DIR/history\nhistory"
+run_test "HISTSIZE=2\nset -o history\necho a\necho b\nhistory"
+
+echo -e "\n26. Testing HISTORY EXPANSION:"
+run_test "set -H -o history\necho one two /tmp/file.txt\necho !!\necho !\$:h !\$:t:r !^\n^tmp^var\necho !-3:1-2 !ec:0"
+run_test "set -H -o history\necho alpha beta\necho !?alp?:s/alpha/a/ !!:gs/a/A/\necho '!!' \\\\!! a!=b\necho !nosuch\necho \$?"
+run_test "echo one; echo !!"
+
+echo -e "\n27. Testing PROGRAMMABLE COMPLETION:"
+run_test "compgen -W 'start stop status restart' st; compgen -W 'a.txt b.log c.txt' -X '*.txt'; compgen -W 'x y' z; echo \$?"
+run_test "mkdir -p $TEST_DIR/comp/dir; touch $TEST_DIR/comp/file; cd $TEST_DIR/comp; compgen -d; compgen -f; compgen -W 'one two' -P '<' -S '>'"
+run_test "mkdir -p $TEST_DIR/comp/src; touch $TEST_DIR/comp/src/main.go $TEST_DIR/comp/src/notes.txt; cd $TEST_DIR/comp; compgen -f -X '*.go' src/; compgen -f -X '*.txt' src/notes; echo \$?"
+run_test "f() { COMPREPLY=(\$1 \$2 \$3); }; compgen -F f ar; compgen -b hi; compgen -A function"
+run_test "complete -W 'start stop' -o nospace svc; complete -F _svc -d 'my tool'; complete -p; complete -r svc; complete -p svc; echo \$?"
+run_test "complete -o bogus x; echo \$?; complete -A bogus x; echo \$?"
+
+echo -e "\n28. Testing EXIT COMMAND:"
 run_test "exit"
 
 # Cleanup
@@ -101,11 +245,39 @@ echo "✅ Environment variables \$VAR"
 echo "✅ Redirections >, >>, <"
 echo "✅ Error handling"
 echo "✅ Complex combinations"
+echo "✅ Functions with local and return"
+echo "✅ Aliases: alias, unalias"
+echo "✅ Filename globbing: *, ?, [...], **"
+echo "✅ Brace expansion: {a,b}, {1..10..2}"
+echo "✅ Tilde expansion: ~, ~user, ~+, ~-"
+echo "✅ Parameter expansion: \${VAR:-x}, \${#VAR}, \${VAR%pat}, \${VAR/pat/rep}"
+echo "✅ Special parameters: \$?, \$\$, \$!, \$#, \$@, \$*, \$0, \$-"
+echo "✅ Positional parameters: \$1, \${10}, shift, set --"
+echo "✅ Arrays: a=(x y), \${a[i]}, \${a[@]}, \${#a[@]}, a+=(w), declare -A"
+echo "✅ Variable builtins: export, unset, set, env"
+echo "✅ Variable assignments: NAME=value, NAME=value cmd"
+echo "✅ Variable attributes: readonly, declare -i/-l/-u/-n/-x/-r, declare -p"
+echo "✅ Scripts: minishell script [args], -c, -s, #! and exit status"
+echo "✅ Source: source file [args], . file, PATH search, return"
+echo "✅ Interactive mode: prompts only on a terminal (or with -i), written to stderr"
+echo "✅ Editing modes: set -o emacs / set -o vi"
+echo "✅ History: history [n], -c, -d N, -w, -r, HISTFILE/HISTSIZE/HISTFILESIZE"
+echo "✅ History expansion: !!, !n, !-n, !prefix, !\$, ^old^new, :h :t :r :s"
+echo "✅ Programmable completion: complete -W/-F/-f/-d/-o/-X/-p/-r, compgen"
 echo "✅ Exit command"
 echo ""
 echo "=== Manual testing required for: ==="
 echo "• Ctrl+D (EOF) handling"
 echo "• Ctrl+C (interrupt) handling" 
+echo "• Line editing: arrows, Home/End, Ctrl+A/E/K/U/W/Y, Alt+B/F/D/Y, Ctrl+_ undo, terminal resize"
+echo "• History navigation: Up/Down, Ctrl+P/N, Alt+</Alt+>, vi k/j; ~/.minishell_history saved on exit"
+echo "• Incremental history search: Ctrl+R / Ctrl+S, Enter, Esc, Ctrl+G, match highlighting"
+echo "• Tab completion: commands (PATH, builtins, aliases, functions), files with escaping, \$VAR, double Tab listing"
+echo "• Programmable completion in the editor: complete -F with COMP_WORDS/COMP_CWORD/COMPREPLY, -o default fallback"
+echo "• Option completion: ls --al<Tab>, ls -<Tab><Tab> with descriptions from man pages or --help, cached in ~/.cache/minishell/options"
+echo "• Syntax highlighting: known/unknown commands, strings, \$VAR, operators, redirections, unclosed quotes in red; MINISHELL_COLORS scheme"
+echo "• History autosuggestions: dimmed text after the cursor, same directory preferred; Right accepts all, Alt+F one word"
+echo "• Vi mode (set -o vi): Esc, motions w b e 0 \$ f t ; , operators d c y, counts, . repeat, u undo, cursor shape"
 echo "• Background processes with &"
 echo "• Signal handling in subprocesses"
 echo "=== Comprehensive test completed ==="
\ No newline at end of file